@@ -3,6 +3,8 @@
 package clr
 
 import (
+	"fmt"
+	"runtime"
 	"syscall"
 	"unsafe"
 
@@ -96,6 +98,7 @@ func GetAppDomain(runtimeHost *ICORRuntimeHost) (appDomain *AppDomain, err error
 		return
 	}
 	iu := NewIUnknownFromPtr(pIUnknown)
+	defer iu.Release()
 	hr = iu.QueryInterface(&IID_AppDomain, &pAppDomain)
 	err = checkOK(hr, "IUnknown.QueryInterface")
 	return NewAppDomainFromPtr(pAppDomain), err
@@ -145,6 +148,16 @@ func (obj *AppDomain) GetHashCode() uintptr {
 	return ret
 }
 
+func (obj *AppDomain) GetType(pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetType,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
 func (obj *AppDomain) Load_3(pRawAssembly uintptr, asmbly *uintptr) uintptr {
 	ret, _, _ := syscall.Syscall(
 		obj.vtbl.Load_3,
@@ -154,3 +167,597 @@ func (obj *AppDomain) Load_3(pRawAssembly uintptr, asmbly *uintptr) uintptr {
 		uintptr(unsafe.Pointer(asmbly)))
 	return ret
 }
+
+// LoadAssembly loads an assembly into the AppDomain from its raw bytes
+func (obj *AppDomain) LoadAssembly(rawAssembly []byte) (*Assembly, error) {
+	if len(rawAssembly) == 0 {
+		return nil, fmt.Errorf("can't load an assembly from an empty byte array")
+	}
+	pRawAssembly, err := CreateSafeArray(rawAssembly)
+	if err != nil {
+		return nil, err
+	}
+	defer SafeArrayDestroy(pRawAssembly)
+	var pAssembly uintptr
+	hr := obj.Load_3(uintptr(pRawAssembly), &pAssembly)
+	err = checkOK(hr, "appDomain.Load_3")
+	if err != nil {
+		return nil, err
+	}
+	return NewAssemblyFromPtr(pAssembly), nil
+}
+
+func (obj *AppDomain) Load_2(assemblyString uintptr, asmbly *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Load_2,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyString,
+		uintptr(unsafe.Pointer(asmbly)))
+	return ret
+}
+
+// LoadAssemblyByName loads an assembly into the AppDomain from its display name (e.g. "System.Management" or
+// "System.Xml, Version=4.0.0.0, Culture=neutral, PublicKeyToken=b77a5c561934e089") using the normal CLR binding rules,
+// so framework assemblies can be pulled out of the GAC
+func (obj *AppDomain) LoadAssemblyByName(name string) (*Assembly, error) {
+	bstr, err := SysAllocString(name)
+	if err != nil {
+		return nil, err
+	}
+	defer SysFreeString(bstr)
+	var pAssembly uintptr
+	hr := obj.Load_2(uintptr(bstr), &pAssembly)
+	err = checkOK(hr, "appDomain.Load_2")
+	if err != nil {
+		return nil, err
+	}
+	return NewAssemblyFromPtr(pAssembly), nil
+}
+
+func (obj *AppDomain) Load_4(pRawAssembly uintptr, pRawSymbolStore uintptr, asmbly *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.Load_4,
+		4,
+		uintptr(unsafe.Pointer(obj)),
+		pRawAssembly,
+		pRawSymbolStore,
+		uintptr(unsafe.Pointer(asmbly)),
+		0,
+		0)
+	return ret
+}
+
+// LoadAssemblyWithSymbols loads an assembly from memory together with its debug symbols (the raw bytes of its PDB),
+// so exceptions thrown from the in-memory code carry file names and line numbers in their stack traces. Use
+// LoadAssembly for an assembly without symbols
+func (obj *AppDomain) LoadAssemblyWithSymbols(rawAssembly, rawSymbolStore []byte) (*Assembly, error) {
+	if len(rawAssembly) == 0 {
+		return nil, fmt.Errorf("can't load an assembly from an empty byte array")
+	}
+	if len(rawSymbolStore) == 0 {
+		return nil, fmt.Errorf("can't load symbols from an empty byte array")
+	}
+	pRawAssembly, err := CreateSafeArray(rawAssembly)
+	if err != nil {
+		return nil, err
+	}
+	defer SafeArrayDestroy(pRawAssembly)
+	pRawSymbolStore, err := CreateSafeArray(rawSymbolStore)
+	if err != nil {
+		return nil, err
+	}
+	defer SafeArrayDestroy(pRawSymbolStore)
+	var pAssembly uintptr
+	hr := obj.Load_4(uintptr(pRawAssembly), uintptr(pRawSymbolStore), &pAssembly)
+	err = checkOK(hr, "appDomain.Load_4")
+	if err != nil {
+		return nil, err
+	}
+	return NewAssemblyFromPtr(pAssembly), nil
+}
+
+func (obj *AppDomain) ExecuteAssembly(assemblyFile uintptr, assemblySecurity uintptr, pRetVal *int32) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.ExecuteAssembly,
+		4,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyFile,
+		assemblySecurity,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) ExecuteAssembly_2(assemblyFile uintptr, pRetVal *int32) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.ExecuteAssembly_2,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyFile,
+		uintptr(unsafe.Pointer(pRetVal)))
+	return ret
+}
+
+func (obj *AppDomain) ExecuteAssembly_3(assemblyFile uintptr, assemblySecurity uintptr, args uintptr, pRetVal *int32) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.ExecuteAssembly_3,
+		5,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyFile,
+		assemblySecurity,
+		args,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+// ExecuteAssemblyFile has the CLR load and run the entry point of an executable on disk in this AppDomain, passing args
+// as its command line. It returns the exit code from the assembly's Main, or a *ManagedError describing the exception if
+// the assembly threw one
+func (obj *AppDomain) ExecuteAssemblyFile(path string, args []string) (int32, error) {
+	bstrPath, err := SysAllocString(path)
+	if err != nil {
+		return -1, err
+	}
+	defer SysFreeString(bstrPath)
+
+	// the error information for a failed call is stored per thread, so we can't be moved until we've read it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var retCode int32
+	if len(args) == 0 {
+		hr := obj.ExecuteAssembly_2(uintptr(bstrPath), &retCode)
+		return retCode, checkManagedOK(hr, "appDomain.ExecuteAssembly_2")
+	}
+	pArgs, err := CreateStringSafeArray(args)
+	if err != nil {
+		return -1, err
+	}
+	defer SafeArrayDestroy(pArgs)
+	hr := obj.ExecuteAssembly_3(uintptr(bstrPath), 0, uintptr(pArgs), &retCode)
+	return retCode, checkManagedOK(hr, "appDomain.ExecuteAssembly_3")
+}
+
+func (obj *AppDomain) GetAssemblies(pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetAssemblies,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+// Assemblies returns every assembly that is currently loaded into the AppDomain. The caller owns a reference to each
+// Assembly returned and should Release them when done
+func (obj *AppDomain) Assemblies() ([]*Assembly, error) {
+	var pAssemblies uintptr
+	hr := obj.GetAssemblies(&pAssemblies)
+	err := checkOK(hr, "appDomain.GetAssemblies")
+	if err != nil {
+		return nil, err
+	}
+	ptrs, err := safeArrayInterfaces(unsafe.Pointer(pAssemblies), &IID_Assembly)
+	assemblies := make([]*Assembly, len(ptrs))
+	for i, ptr := range ptrs {
+		assemblies[i] = NewAssemblyFromPtr(ptr)
+	}
+	return assemblies, err
+}
+
+func (obj *AppDomain) SetData(name uintptr, data Variant) uintptr {
+	if variantByValue {
+		v := data.words()
+		ret, _, _ := syscall.Syscall6(
+			obj.vtbl.SetData,
+			6,
+			uintptr(unsafe.Pointer(obj)),
+			name,
+			v[0],
+			v[1],
+			v[2],
+			v[3])
+		return ret
+	}
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.SetData,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		name,
+		uintptr(unsafe.Pointer(&data)))
+	return ret
+}
+
+func (obj *AppDomain) GetData(name uintptr, pRetVal *Variant) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetData,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		name,
+		uintptr(unsafe.Pointer(pRetVal)))
+	return ret
+}
+
+// SetDataValue stores a value in the AppDomain's data slots under name, where managed code can read it back with
+// AppDomain.CurrentDomain.GetData(name). The value must be one of the types supported by NewVariant
+func (obj *AppDomain) SetDataValue(name string, value interface{}) error {
+	data, err := NewVariant(value)
+	if err != nil {
+		return err
+	}
+	defer VariantClear(&data)
+	bstrName, err := SysAllocString(name)
+	if err != nil {
+		return err
+	}
+	defer SysFreeString(bstrName)
+	hr := obj.SetData(uintptr(bstrName), data)
+	return checkOK(hr, "appDomain.SetData")
+}
+
+// GetDataValue reads the value stored in the AppDomain's data slots under name, by Go or by managed code with
+// AppDomain.CurrentDomain.SetData, and converts it to a Go value. It returns nil if nothing is stored under name
+func (obj *AppDomain) GetDataValue(name string) (interface{}, error) {
+	bstrName, err := SysAllocString(name)
+	if err != nil {
+		return nil, err
+	}
+	defer SysFreeString(bstrName)
+	var data Variant
+	hr := obj.GetData(uintptr(bstrName), &data)
+	err = checkOK(hr, "appDomain.GetData")
+	if err != nil {
+		return nil, err
+	}
+	defer VariantClear(&data)
+	return data.ToGo()
+}
+
+func (obj *AppDomain) CreateInstance(assemblyName uintptr, typeName uintptr, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.CreateInstance,
+		4,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyName,
+		typeName,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) CreateInstanceFrom(assemblyFile uintptr, typeName uintptr, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.CreateInstanceFrom,
+		4,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyFile,
+		typeName,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) CreateInstance_2(assemblyName uintptr, typeName uintptr, activationAttributes uintptr, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.CreateInstance_2,
+		5,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyName,
+		typeName,
+		activationAttributes,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+func (obj *AppDomain) CreateInstanceFrom_2(assemblyFile uintptr, typeName uintptr, activationAttributes uintptr, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.CreateInstanceFrom_2,
+		5,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyFile,
+		typeName,
+		activationAttributes,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+func (obj *AppDomain) CreateInstance_3(assemblyName uintptr, typeName uintptr, ignoreCase int16, bindingAttr BindingFlags, binder uintptr, args uintptr, culture uintptr, activationAttributes uintptr, securityAttributes uintptr, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall12(
+		obj.vtbl.CreateInstance_3,
+		11,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyName,
+		typeName,
+		uintptr(ignoreCase),
+		uintptr(bindingAttr),
+		binder,
+		args,
+		culture,
+		activationAttributes,
+		securityAttributes,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+func (obj *AppDomain) CreateInstanceFrom_3(assemblyFile uintptr, typeName uintptr, ignoreCase int16, bindingAttr BindingFlags, binder uintptr, args uintptr, culture uintptr, activationAttributes uintptr, securityAttributes uintptr, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall12(
+		obj.vtbl.CreateInstanceFrom_3,
+		11,
+		uintptr(unsafe.Pointer(obj)),
+		assemblyFile,
+		typeName,
+		uintptr(ignoreCase),
+		uintptr(bindingAttr),
+		binder,
+		args,
+		culture,
+		activationAttributes,
+		securityAttributes,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+// CreateObject creates an instance of typeName from the assembly with the display name assemblyName and returns a
+// handle to the new managed object. Any args are converted with NewVariant and passed to the matching public constructor
+func (obj *AppDomain) CreateObject(assemblyName, typeName string, args ...interface{}) (*Object, error) {
+	return obj.createObject(false, assemblyName, typeName, args)
+}
+
+// CreateObjectFrom works like CreateObject, but loads the type from the assembly file at assemblyFile
+func (obj *AppDomain) CreateObjectFrom(assemblyFile, typeName string, args ...interface{}) (*Object, error) {
+	return obj.createObject(true, assemblyFile, typeName, args)
+}
+
+func (obj *AppDomain) createObject(fromFile bool, assembly, typeName string, args []interface{}) (*Object, error) {
+	bstrAssembly, err := SysAllocString(assembly)
+	if err != nil {
+		return nil, err
+	}
+	defer SysFreeString(bstrAssembly)
+	bstrTypeName, err := SysAllocString(typeName)
+	if err != nil {
+		return nil, err
+	}
+	defer SysFreeString(bstrTypeName)
+
+	var pObjectHandle, hr uintptr
+	var caller string
+	if len(args) == 0 {
+		if fromFile {
+			caller = "appDomain.CreateInstanceFrom"
+			hr = obj.CreateInstanceFrom(uintptr(bstrAssembly), uintptr(bstrTypeName), &pObjectHandle)
+		} else {
+			caller = "appDomain.CreateInstance"
+			hr = obj.CreateInstance(uintptr(bstrAssembly), uintptr(bstrTypeName), &pObjectHandle)
+		}
+	} else {
+		pArgs, err := CreateVariantSafeArray(args)
+		if err != nil {
+			return nil, err
+		}
+		defer SafeArrayDestroy(pArgs)
+		bindingAttr := BindingFlagsInstance | BindingFlagsPublic | BindingFlagsCreateInstance
+		if fromFile {
+			caller = "appDomain.CreateInstanceFrom_3"
+			hr = obj.CreateInstanceFrom_3(uintptr(bstrAssembly), uintptr(bstrTypeName), 0, bindingAttr, 0, uintptr(pArgs), 0, 0, 0, &pObjectHandle)
+		} else {
+			caller = "appDomain.CreateInstance_3"
+			hr = obj.CreateInstance_3(uintptr(bstrAssembly), uintptr(bstrTypeName), 0, bindingAttr, 0, uintptr(pArgs), 0, 0, 0, &pObjectHandle)
+		}
+	}
+	err = checkOK(hr, caller)
+	if err != nil {
+		return nil, err
+	}
+	return unwrapObjectHandle(pObjectHandle)
+}
+
+func (obj *AppDomain) AddAssemblyResolve(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.add_AssemblyResolve,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) RemoveAssemblyResolve(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.remove_AssemblyResolve,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) AddDomainUnload(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.add_DomainUnload,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) RemoveDomainUnload(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.remove_DomainUnload,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) AddProcessExit(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.add_ProcessExit,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) RemoveProcessExit(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.remove_ProcessExit,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) AddUnhandledException(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.add_UnhandledException,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+func (obj *AppDomain) RemoveUnhandledException(value uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.remove_UnhandledException,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		value,
+		0)
+	return ret
+}
+
+// GetFriendlyName returns the friendly name of the AppDomain, which for the default domain is the executable's name
+func (obj *AppDomain) GetFriendlyName() (string, error) {
+	return obj.getString(obj.vtbl.get_FriendlyName, "appDomain.get_FriendlyName")
+}
+
+// GetBaseDirectory returns the base directory the assembly resolver uses to probe for assemblies
+func (obj *AppDomain) GetBaseDirectory() (string, error) {
+	return obj.getString(obj.vtbl.get_BaseDirectory, "appDomain.get_BaseDirectory")
+}
+
+// GetRelativeSearchPath returns the path under the base directory where the assembly resolver probes for private
+// assemblies
+func (obj *AppDomain) GetRelativeSearchPath() (string, error) {
+	return obj.getString(obj.vtbl.get_RelativeSearchPath, "appDomain.get_RelativeSearchPath")
+}
+
+// GetShadowCopyFiles returns whether the AppDomain is configured to shadow copy the assemblies it loads
+func (obj *AppDomain) GetShadowCopyFiles() (bool, error) {
+	var shadowCopyFiles int16 // VARIANT_BOOL
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.get_ShadowCopyFiles,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&shadowCopyFiles)),
+		0)
+	if err := checkOK(ret, "appDomain.get_ShadowCopyFiles"); err != nil {
+		return false, err
+	}
+	return shadowCopyFiles != 0, nil
+}
+
+// AppendPrivatePath adds a directory, relative to the base directory, to the list of paths probed for private
+// assemblies
+func (obj *AppDomain) AppendPrivatePath(path string) error {
+	return obj.setString(obj.vtbl.AppendPrivatePath, "appDomain.AppendPrivatePath", path)
+}
+
+// ClearPrivatePath resets the list of paths probed for private assemblies
+func (obj *AppDomain) ClearPrivatePath() error {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.ClearPrivatePath,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return checkOK(ret, "appDomain.ClearPrivatePath")
+}
+
+// SetShadowCopyPath sets the semicolon separated list of directories whose assemblies are shadow copied
+func (obj *AppDomain) SetShadowCopyPath(path string) error {
+	return obj.setString(obj.vtbl.SetShadowCopyPath, "appDomain.SetShadowCopyPath", path)
+}
+
+// ClearShadowCopyPath resets the list of directories whose assemblies are shadow copied, so that every directory is
+// shadow copied again
+func (obj *AppDomain) ClearShadowCopyPath() error {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.ClearShadowCopyPath,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return checkOK(ret, "appDomain.ClearShadowCopyPath")
+}
+
+// SetCachePath sets the directory that shadow copied assemblies are copied to
+func (obj *AppDomain) SetCachePath(path string) error {
+	return obj.setString(obj.vtbl.SetCachePath, "appDomain.SetCachePath", path)
+}
+
+// SetShadowCopyFiles turns on shadow copying for the AppDomain, so assemblies are copied to the cache path and loaded
+// from there, leaving the original files unlocked so they can be replaced while loaded. It is not part of the _AppDomain
+// interface so it is called through reflection, and like the other shadow copy settings it only affects assemblies
+// loaded after it is called
+func (obj *AppDomain) SetShadowCopyFiles() error {
+	var pType uintptr
+	hr := obj.GetType(&pType)
+	err := checkOK(hr, "appDomain.GetType")
+	if err != nil {
+		return err
+	}
+	domainType := NewTypeFromPtr(pType)
+	defer domainType.Release()
+	ret, err := domainType.invokeMember("SetShadowCopyFiles", BindingFlagsInvokeMethod|BindingFlagsInstance|BindingFlagsPublic, obj, nil)
+	if err != nil {
+		return err
+	}
+	return VariantClear(&ret)
+}
+
+// getString calls one of the AppDomain's methods that returns a BSTR
+func (obj *AppDomain) getString(method uintptr, caller string) (string, error) {
+	var bstr uintptr
+	ret, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if err := checkOK(ret, caller); err != nil {
+		return "", err
+	}
+	return readBSTR(bstr), nil
+}
+
+// setString calls one of the AppDomain's methods that takes a single BSTR
+func (obj *AppDomain) setString(method uintptr, caller string, value string) error {
+	bstr, err := SysAllocString(value)
+	if err != nil {
+		return err
+	}
+	defer SysFreeString(bstr)
+	ret, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(bstr),
+		0)
+	return checkOK(ret, caller)
+}
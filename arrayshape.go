@@ -0,0 +1,109 @@
+package clr
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Working out the shape and the element type of the safe array for a Go slice doesn't need Windows, so this file is
+// built on every platform
+
+// arrayShape returns the lengths of the dimensions of v, a slice or array nested once per dimension, and the type of
+// its elements. Every level of nesting is a dimension and its length is taken from its first element
+func arrayShape(v reflect.Value) ([]int, reflect.Type) {
+	var lengths []int
+	elemType := v.Type()
+	for first := v; elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Array; elemType = elemType.Elem() {
+		lengths = append(lengths, first.Len())
+		if first.Len() > 0 {
+			first = first.Index(0)
+		} else {
+			first = reflect.Zero(elemType.Elem())
+		}
+	}
+	return lengths, elemType
+}
+
+// elementVartype returns the type of safe array elements that values of Go type t are stored as. Handles to managed
+// objects aren't known here, see safeArrayVartype
+func elementVartype(t reflect.Type) (uint16, error) {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return VT_DATE, nil
+	case reflect.TypeOf(Currency(0)):
+		return VT_CY, nil
+	case reflect.TypeOf(Decimal{}):
+		return VT_DECIMAL, nil
+	case reflect.TypeOf(Variant{}):
+		return VT_VARIANT, nil
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return VT_VARIANT, nil
+	case reflect.String:
+		return VT_BSTR, nil
+	case reflect.Bool:
+		return VT_BOOL, nil
+	case reflect.Int8:
+		return VT_I1, nil
+	case reflect.Int16:
+		return VT_I2, nil
+	case reflect.Int32, reflect.Int:
+		return VT_I4, nil
+	case reflect.Int64:
+		return VT_I8, nil
+	case reflect.Uint8:
+		return VT_UI1, nil
+	case reflect.Uint16:
+		return VT_UI2, nil
+	case reflect.Uint32, reflect.Uint:
+		return VT_UI4, nil
+	case reflect.Uint64:
+		return VT_UI8, nil
+	case reflect.Float32:
+		return VT_R4, nil
+	case reflect.Float64:
+		return VT_R8, nil
+	default:
+		return 0, fmt.Errorf("can't create a safe array of %s", t)
+	}
+}
+
+// arrayElements calls fn with the indices and the value of every element of v, which has the dimensions in lengths as
+// returned by arrayShape. It fails if v is jagged. ints and uints are passed to fn as int32 and uint32, the type of the
+// array's elements, and it fails if one doesn't fit. indices is reused, so fn must not hold on to it
+func arrayElements(v reflect.Value, lengths []int, fn func(indices []int, value interface{}) error) error {
+	return arrayElementsAt(v, lengths, nil, fn)
+}
+
+func arrayElementsAt(v reflect.Value, lengths, indices []int, fn func(indices []int, value interface{}) error) error {
+	level := len(indices)
+	if level < len(lengths) {
+		if v.Len() != lengths[level] {
+			return fmt.Errorf("can't create a safe array from a jagged slice, dimension %d has lengths %d and %d", level, lengths[level], v.Len())
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := arrayElementsAt(v.Index(i), lengths, append(indices, i), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	value := v.Interface()
+	// int and uint would become 64-bit values when they don't fit, but the array's elements are 32-bit
+	switch i := value.(type) {
+	case int:
+		if int(int32(i)) != i {
+			return fmt.Errorf("%d at %v doesn't fit in an int", i, indices)
+		}
+		value = int32(i)
+	case uint:
+		if uint(uint32(i)) != i {
+			return fmt.Errorf("%d at %v doesn't fit in a uint", i, indices)
+		}
+		value = uint32(i)
+	}
+	return fn(indices, value)
+}
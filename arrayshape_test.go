@@ -0,0 +1,126 @@
+package clr
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestArrayShape(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		lengths  []int
+		elemType reflect.Type
+	}{
+		{[]int32{1, 2, 3}, []int{3}, reflect.TypeOf(int32(0))},
+		{[][]string{{"a", "b", "c"}, {"d", "e", "f"}}, []int{2, 3}, reflect.TypeOf("")},
+		{[2][3][4]bool{}, []int{2, 3, 4}, reflect.TypeOf(false)},
+		{[][2]float64{{1, 2}}, []int{1, 2}, reflect.TypeOf(float64(0))},
+		// an empty slice still has the dimensions of its type
+		{[][]int{}, []int{0, 0}, reflect.TypeOf(0)},
+		{[]interface{}{[]int{1, 2}}, []int{1}, reflect.TypeOf((*interface{})(nil)).Elem()},
+	}
+	for _, test := range tests {
+		lengths, elemType := arrayShape(reflect.ValueOf(test.value))
+		if !reflect.DeepEqual(lengths, test.lengths) || elemType != test.elemType {
+			t.Errorf("arrayShape(%#v) = %v, %s, want %v, %s", test.value, lengths, elemType, test.lengths, test.elemType)
+		}
+	}
+}
+
+func TestElementVartype(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		vt    uint16
+	}{
+		{"", VT_BSTR},
+		{false, VT_BOOL},
+		{int8(0), VT_I1},
+		{0, VT_I4},
+		{int64(0), VT_I8},
+		{uint8(0), VT_UI1},
+		{uint(0), VT_UI4},
+		{uint64(0), VT_UI8},
+		{float32(0), VT_R4},
+		{float64(0), VT_R8},
+		{time.Time{}, VT_DATE},
+		{Currency(0), VT_CY},
+		{Decimal{}, VT_DECIMAL},
+		{Variant{}, VT_VARIANT},
+	}
+	for _, test := range tests {
+		if vt, err := elementVartype(reflect.TypeOf(test.value)); err != nil || vt != test.vt {
+			t.Errorf("elementVartype(%T) = 0x%04x, %v, want 0x%04x", test.value, vt, err, test.vt)
+		}
+	}
+	if vt, err := elementVartype(reflect.TypeOf([]interface{}{}).Elem()); err != nil || vt != VT_VARIANT {
+		t.Errorf("elementVartype(interface{}) = 0x%04x, %v, want 0x%04x", vt, err, VT_VARIANT)
+	}
+	for _, value := range []interface{}{struct{}{}, map[string]int{}, new(int), complex64(0)} {
+		if vt, err := elementVartype(reflect.TypeOf(value)); err == nil {
+			t.Errorf("elementVartype(%T) = 0x%04x, want an error", value, vt)
+		}
+	}
+}
+
+type arrayElement struct {
+	indices []int
+	value   interface{}
+}
+
+func collectElements(value interface{}) ([]arrayElement, error) {
+	v := reflect.ValueOf(value)
+	lengths, _ := arrayShape(v)
+	var elements []arrayElement
+	err := arrayElements(v, lengths, func(indices []int, value interface{}) error {
+		elements = append(elements, arrayElement{append([]int(nil), indices...), value})
+		return nil
+	})
+	return elements, err
+}
+
+func TestArrayElements(t *testing.T) {
+	got, err := collectElements([][]int{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// in declaration order, with the int values as the int32 they are stored as
+	want := []arrayElement{
+		{[]int{0, 0}, int32(1)},
+		{[]int{0, 1}, int32(2)},
+		{[]int{0, 2}, int32(3)},
+		{[]int{1, 0}, int32(4)},
+		{[]int{1, 1}, int32(5)},
+		{[]int{1, 2}, int32(6)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got, err = collectElements([]uint{math.MaxUint32})
+	if err != nil || !reflect.DeepEqual(got, []arrayElement{{[]int{0}, uint32(math.MaxUint32)}}) {
+		t.Errorf("got %v, %v for []uint{MaxUint32}", got, err)
+	}
+	if got, err := collectElements([][]int{}); err != nil || len(got) != 0 {
+		t.Errorf("got %v, %v for an empty slice", got, err)
+	}
+}
+
+func TestArrayElementsInvalid(t *testing.T) {
+	values := []interface{}{
+		[][]int32{{1, 2, 3}, {4, 5}},
+		[][]int32{{1}, {2, 3}},
+		[][][]bool{{{true}}, {{true, false}}},
+	}
+	if strconv.IntSize == 64 {
+		big := int64(math.MaxInt32) + 1
+		values = append(values, []int{int(big)}, []int{-int(big) - 1}, []uint{uint(big) * 2})
+	}
+	for _, value := range values {
+		if got, err := collectElements(value); err == nil {
+			t.Errorf("arrayElements(%v) = %v, want an error", value, got)
+		}
+	}
+}
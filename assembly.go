@@ -3,9 +3,11 @@
 package clr
 
 import (
-	"golang.org/x/sys/windows"
+	"fmt"
 	"syscall"
 	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
 // from mscorlib.tlh
@@ -111,3 +113,141 @@ func (obj *Assembly) GetEntryPoint(pMethodInfo *uintptr) uintptr {
 		0)
 	return ret
 }
+
+// GetFullName returns the display name of the assembly, e.g. "mscorlib, Version=4.0.0.0, Culture=neutral, PublicKeyToken=b77a5c561934e089"
+func (obj *Assembly) GetFullName() (string, error) {
+	var bstr uintptr
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.get_FullName,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if err := checkOK(ret, "assembly.get_FullName"); err != nil {
+		return "", err
+	}
+	return readBSTR(bstr), nil
+}
+
+// GetLocation returns the full path of the file the assembly was loaded from, or an empty string if it was loaded
+// from memory
+func (obj *Assembly) GetLocation() (string, error) {
+	var bstr uintptr
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.get_Location,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if err := checkOK(ret, "assembly.get_Location"); err != nil {
+		return "", err
+	}
+	return readBSTR(bstr), nil
+}
+
+// GetGlobalAssemblyCache returns whether the assembly was loaded from the global assembly cache
+func (obj *Assembly) GetGlobalAssemblyCache() (bool, error) {
+	var inGAC int16 // VARIANT_BOOL
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.get_GlobalAssemblyCache,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&inGAC)),
+		0)
+	if err := checkOK(ret, "assembly.get_GlobalAssemblyCache"); err != nil {
+		return false, err
+	}
+	return inGAC != 0, nil
+}
+
+func (obj *Assembly) GetType_2(name uintptr, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetType_2,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		name,
+		uintptr(unsafe.Pointer(pRetVal)))
+	return ret
+}
+
+// GetTypeByName returns the type with the given full name (e.g. "System.Text.StringBuilder") from the assembly
+func (obj *Assembly) GetTypeByName(name string) (*Type, error) {
+	bstrName, err := SysAllocString(name)
+	if err != nil {
+		return nil, err
+	}
+	defer SysFreeString(bstrName)
+	var pType uintptr
+	hr := obj.GetType_2(uintptr(bstrName), &pType)
+	err = checkOK(hr, "assembly.GetType_2")
+	if err != nil {
+		return nil, err
+	}
+	if pType == 0 {
+		return nil, fmt.Errorf("type %s was not found in the assembly", name)
+	}
+	return NewTypeFromPtr(pType), nil
+}
+
+func (obj *Assembly) GetTypes(pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetTypes,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+func (obj *Assembly) GetExportedTypes(pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetExportedTypes,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+// Types returns every type defined in the assembly. The caller owns a reference to each Type returned
+func (obj *Assembly) Types() ([]*Type, error) {
+	var pTypes uintptr
+	hr := obj.GetTypes(&pTypes)
+	err := checkOK(hr, "assembly.GetTypes")
+	if err != nil {
+		return nil, err
+	}
+	return typesFromSafeArray(pTypes)
+}
+
+// ExportedTypes returns the public types defined in the assembly. The caller owns a reference to each Type returned
+func (obj *Assembly) ExportedTypes() ([]*Type, error) {
+	var pTypes uintptr
+	hr := obj.GetExportedTypes(&pTypes)
+	err := checkOK(hr, "assembly.GetExportedTypes")
+	if err != nil {
+		return nil, err
+	}
+	return typesFromSafeArray(pTypes)
+}
+
+// COR_E_AMBIGUOUSMATCH is the HRESULT of the AmbiguousMatchException thrown when more than one overload of a method
+// matches the arguments it's called with
+const COR_E_AMBIGUOUSMATCH = 0x8000211D
+
+// CallStatic calls the static method methodName of the type typeName (e.g. "Namespace.Type") in the assembly, which
+// may have been loaded from memory. The args are converted with NewVariant and the method's return value is converted
+// back with ToGo, so a void method returns nil. If the method is overloaded the CLR's default binder picks the overload
+// whose parameters match the types of the converted args
+func (obj *Assembly) CallStatic(typeName, methodName string, args ...interface{}) (interface{}, error) {
+	t, err := obj.GetTypeByName(typeName)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Release()
+	ret, err := t.InvokeMember(methodName, BindingFlagsInvokeMethod|BindingFlagsStatic|BindingFlagsPublic|BindingFlagsNonPublic, nil, args...)
+	if managedErr, ok := err.(*ManagedError); ok && managedErr.HResult == COR_E_AMBIGUOUSMATCH {
+		return nil, fmt.Errorf("%d argument(s) match more than one overload of %s.%s: %w", len(args), typeName, methodName, err)
+	}
+	return ret, err
+}
@@ -0,0 +1,233 @@
+// +build windows
+
+package clr
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// The AppDomain events take managed delegates, which we can't create from a Go function directly. Instead we compile
+// a small lambda with System.Linq.Expressions (so .NET 4 is required) that looks like:
+//
+//	(object sender, TEventArgs e) => ((Func<IntPtr, TResult>)(ptr =>
+//		ptr == IntPtr.Zero ? null : Marshal.GetObjectForIUnknown(ptr) as TResult))(
+//		((ObjectCreationDelegate)Marshal.GetDelegateForFunctionPointer(callback, typeof(ObjectCreationDelegate)))(
+//			Marshal.GetIUnknownForObject(new object[] { id, e })))
+//
+// ObjectCreationDelegate is used because it is one of the few delegates in mscorlib that only takes and returns an
+// IntPtr, so it can call a syscall.NewCallback function pointer the same way on every architecture. The runtime only
+// has room for a couple thousand of those and never frees them, so every delegate calls the same callback and passes
+// it the id of the Go function to run.
+
+const systemCore = "System.Core, Version=4.0.0.0, Culture=neutral, PublicKeyToken=b77a5c561934e089"
+
+// eventCallback is the callback behind every delegate we build
+var eventCallback = syscall.NewCallback(dispatchEvent)
+
+// eventHandlers holds the Go functions the delegates call, by id
+var (
+	eventHandlersMu sync.Mutex
+	eventHandlers   = make(map[int64]func(args *Object) uintptr)
+	lastEventID     int64
+)
+
+// registerEventHandler adds fn to eventHandlers and returns the id to build its delegate with
+func registerEventHandler(fn func(args *Object) uintptr) int64 {
+	eventHandlersMu.Lock()
+	defer eventHandlersMu.Unlock()
+	lastEventID++
+	eventHandlers[lastEventID] = fn
+	return lastEventID
+}
+
+func unregisterEventHandler(id int64) {
+	eventHandlersMu.Lock()
+	defer eventHandlersMu.Unlock()
+	delete(eventHandlers, id)
+}
+
+// dispatchEvent receives the object[] { id, e } built by a delegate and returns what the Go function registered under
+// id returns for e. It returns 0, which the delegate turns into null, if anything goes wrong
+func dispatchEvent(pArgs uintptr) uintptr {
+	iu := NewIUnknownFromPtr(pArgs)
+	defer iu.Release()
+	array, err := newObjectFromUnknown(iu)
+	if err != nil {
+		return 0
+	}
+	defer array.Release()
+	value, err := array.CallMethod("GetValue", 0)
+	id, ok := value.(int64)
+	if err != nil || !ok {
+		return 0
+	}
+	eventHandlersMu.Lock()
+	fn := eventHandlers[id]
+	eventHandlersMu.Unlock()
+	if fn == nil {
+		return 0
+	}
+	value, err = array.CallMethod("GetValue", 1)
+	args, ok := value.(*Object)
+	if err != nil || !ok {
+		return 0
+	}
+	defer args.Release()
+	return fn(args)
+}
+
+// delegateBuilder builds managed delegates that call back into Go
+type delegateBuilder struct {
+	mscorlib   *Assembly
+	expression *Type // System.Linq.Expressions.Expression
+}
+
+func newDelegateBuilder(domain *AppDomain) (*delegateBuilder, error) {
+	mscorlib, err := domain.LoadAssemblyByName("mscorlib")
+	if err != nil {
+		return nil, err
+	}
+	core, err := domain.LoadAssemblyByName(systemCore)
+	if err != nil {
+		mscorlib.Release()
+		return nil, err
+	}
+	defer core.Release()
+	expression, err := core.GetTypeByName("System.Linq.Expressions.Expression")
+	if err != nil {
+		mscorlib.Release()
+		return nil, err
+	}
+	return &delegateBuilder{mscorlib: mscorlib, expression: expression}, nil
+}
+
+func (b *delegateBuilder) Release() {
+	b.expression.Release()
+	b.mscorlib.Release()
+}
+
+// typeOf returns a type from mscorlib
+func (b *delegateBuilder) typeOf(name string) (*Type, error) {
+	return b.mscorlib.GetTypeByName(name)
+}
+
+// expr calls one of the static factory methods on System.Linq.Expressions.Expression and returns the node it built
+func (b *delegateBuilder) expr(method string, args ...interface{}) (*Object, error) {
+	ret, err := b.expression.invokeMember(method, BindingFlagsInvokeMethod|BindingFlagsStatic|BindingFlagsPublic, nil, args)
+	if err != nil {
+		return nil, err
+	}
+	defer VariantClear(&ret)
+	return NewObjectFromVariant(&ret)
+}
+
+// build compiles a delegate of type delegateType whose parameters are (object sender, argsType e). When invoked, it
+// calls the Go function registered under id with e. If resultType is not nil the pointer returned by the function is
+// turned back into a managed object and returned from the delegate as resultType, or null if it is 0 or isn't one
+func (b *delegateBuilder) build(delegateType, argsType, resultType *Type, id int64) (*Object, error) {
+	var nodes []*Object
+	defer func() {
+		for _, node := range nodes {
+			node.Release()
+		}
+	}()
+	var err error
+	node := func(method string, args ...interface{}) *Object {
+		if err != nil {
+			return nil
+		}
+		var n *Object
+		n, err = b.expr(method, args...)
+		if err == nil {
+			nodes = append(nodes, n)
+		}
+		return n
+	}
+
+	objectType, err := b.typeOf("System.Object")
+	if err != nil {
+		return nil, err
+	}
+	defer objectType.Release()
+	intPtrType, err := b.typeOf("System.IntPtr")
+	if err != nil {
+		return nil, err
+	}
+	defer intPtrType.Release()
+	marshalType, err := b.typeOf("System.Runtime.InteropServices.Marshal")
+	if err != nil {
+		return nil, err
+	}
+	defer marshalType.Release()
+	callbackType, err := b.typeOf("System.Runtime.InteropServices.ObjectCreationDelegate")
+	if err != nil {
+		return nil, err
+	}
+	defer callbackType.Release()
+
+	sender := node("Parameter", objectType, "sender")
+	e := node("Parameter", argsType, "e")
+	args := node("NewArrayInit", objectType, node("Convert", node("Constant", id), objectType), node("Convert", e, objectType))
+	pArgs := node("Call", marshalType, "GetIUnknownForObject", nil, args)
+	fnPtr := node("Convert", node("Constant", int64(eventCallback)), intPtrType)
+	fn := node("Call", marshalType, "GetDelegateForFunctionPointer", nil, fnPtr, node("Constant", callbackType))
+	body := node("Invoke", node("Convert", fn, callbackType), pArgs)
+	if resultType != nil {
+		// GetObjectForIUnknown throws on a null pointer, so it goes through a lambda that checks for one first
+		ptr := node("Parameter", intPtrType, "ptr")
+		isNull := node("Equal", ptr, node("Convert", node("Constant", int64(0)), intPtrType))
+		object := node("TypeAs", node("Call", marshalType, "GetObjectForIUnknown", nil, ptr), resultType)
+		toResult := node("Lambda",
+			node("GetFuncType", intPtrType, resultType),
+			node("Condition", isNull, node("Constant", nil, resultType), object),
+			ptr)
+		body = node("Invoke", toResult, body)
+	}
+	lambda := node("Lambda", delegateType, body, sender, e)
+	if err != nil {
+		return nil, err
+	}
+
+	lambdaType, err := lambda.GetRuntimeType()
+	if err != nil {
+		return nil, err
+	}
+	defer lambdaType.Release()
+	ret, err := lambdaType.invokeMember("Compile", BindingFlagsInvokeMethod|BindingFlagsInstance|BindingFlagsPublic, lambda, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer VariantClear(&ret)
+	return NewObjectFromVariant(&ret)
+}
+
+// EventHandler is a delegate calling back into Go that has been added to one of the AppDomain's events
+type EventHandler struct {
+	delegate *Object
+	id       int64
+	remove   func(value uintptr) uintptr
+	caller   string
+	release  func()
+}
+
+// Remove removes the handler from the event it was added to. The Go function behind it won't be called again
+func (h *EventHandler) Remove() error {
+	if h.delegate == nil {
+		return fmt.Errorf("the event handler has already been removed")
+	}
+	hr := h.remove(uintptr(unsafe.Pointer(h.delegate)))
+	err := checkOK(hr, h.caller)
+	if err != nil {
+		return err
+	}
+	h.delegate.Release()
+	h.delegate = nil
+	unregisterEventHandler(h.id)
+	if h.release != nil {
+		h.release()
+	}
+	return nil
+}
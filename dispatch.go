@@ -0,0 +1,238 @@
+// +build windows
+
+package clr
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// from oaidl.h and oleauto.h
+
+const (
+	DISPATCH_METHOD         = 0x1
+	DISPATCH_PROPERTYGET    = 0x2
+	DISPATCH_PROPERTYPUT    = 0x4
+	DISPATCH_PROPERTYPUTREF = 0x8
+
+	DISPID_PROPERTYPUT = -3
+
+	LOCALE_USER_DEFAULT = 0x400
+)
+
+// HRESULTs returned by IDispatch::Invoke
+const (
+	DISP_E_MEMBERNOTFOUND = 0x80020003
+	DISP_E_PARAMNOTFOUND  = 0x80020004
+	DISP_E_TYPEMISMATCH   = 0x80020005
+	DISP_E_UNKNOWNNAME    = 0x80020006
+	DISP_E_EXCEPTION      = 0x80020009
+	DISP_E_BADPARAMCOUNT  = 0x8002000E
+)
+
+// Dispatch wraps the IDispatch interface, which lets members of an object be called by name without knowing its
+// vtable. QueryInterface a COM-visible managed object for IID_IDispatch to script all of its public members. Every
+// mscorlib interface such as AppDomain, Assembly or MethodInfo also starts with the IDispatch methods, so
+// NewDispatchFromPtr works on them too, but only reaches the members of that interface
+type Dispatch struct {
+	vtbl *DispatchVtbl
+}
+
+type DispatchVtbl struct {
+	QueryInterface   uintptr
+	AddRef           uintptr
+	Release          uintptr
+	GetTypeInfoCount uintptr
+	GetTypeInfo      uintptr
+	GetIDsOfNames    uintptr
+	Invoke           uintptr
+}
+
+// DispParams holds the arguments of an IDispatch::Invoke call. The arguments are stored in reverse order
+type DispParams struct {
+	rgvarg            *Variant
+	rgdispidNamedArgs *int32
+	cArgs             uint32
+	cNamedArgs        uint32
+}
+
+// ExcepInfo describes the exception thrown by a member called through IDispatch::Invoke, which returns
+// DISP_E_EXCEPTION when it is filled in
+type ExcepInfo struct {
+	wCode             uint16
+	wReserved         uint16
+	bstrSource        uintptr
+	bstrDescription   uintptr
+	bstrHelpFile      uintptr
+	dwHelpContext     uint32
+	pvReserved        uintptr
+	pfnDeferredFillIn uintptr
+	scode             uint32
+}
+
+func NewDispatchFromPtr(ppv uintptr) *Dispatch {
+	return (*Dispatch)(unsafe.Pointer(ppv))
+}
+
+func (obj *Dispatch) QueryInterface(riid *windows.GUID, ppvObject *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.QueryInterface,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppvObject)))
+	return ret
+}
+
+func (obj *Dispatch) AddRef() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.AddRef,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *Dispatch) Release() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Release,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *Dispatch) GetIDsOfNames(riid *windows.GUID, rgszNames **uint16, cNames uint32, lcid uint32, rgDispId *int32) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.GetIDsOfNames,
+		6,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(rgszNames)),
+		uintptr(cNames),
+		uintptr(lcid),
+		uintptr(unsafe.Pointer(rgDispId)))
+	return ret
+}
+
+func (obj *Dispatch) Invoke(dispIdMember int32, riid *windows.GUID, lcid uint32, wFlags uint16, pDispParams *DispParams, pVarResult *Variant, pExcepInfo *ExcepInfo, puArgErr *uint32) uintptr {
+	ret, _, _ := syscall.Syscall9(
+		obj.vtbl.Invoke,
+		9,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(dispIdMember),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(lcid),
+		uintptr(wFlags),
+		uintptr(unsafe.Pointer(pDispParams)),
+		uintptr(unsafe.Pointer(pVarResult)),
+		uintptr(unsafe.Pointer(pExcepInfo)),
+		uintptr(unsafe.Pointer(puArgErr)))
+	return ret
+}
+
+// GetIDOfName returns the DISPID of the member called name
+func (obj *Dispatch) GetIDOfName(name string) (int32, error) {
+	pName, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	var dispID int32
+	hr := obj.GetIDsOfNames(&IID_NULL, &pName, 1, LOCALE_USER_DEFAULT, &dispID)
+	err = checkOK(hr, "dispatch.GetIDsOfNames("+name+")")
+	if err != nil {
+		return 0, err
+	}
+	return dispID, nil
+}
+
+// Call calls the method called name with args, which are converted with NewVariant. The result is converted with
+// Variant.ToGo. If the method throws, the error is a *ManagedError describing the exception
+func (obj *Dispatch) Call(name string, args ...interface{}) (interface{}, error) {
+	return obj.invoke(name, DISPATCH_METHOD, args)
+}
+
+// Get reads the property called name. args are only needed for indexed properties
+func (obj *Dispatch) Get(name string, args ...interface{}) (interface{}, error) {
+	return obj.invoke(name, DISPATCH_PROPERTYGET, args)
+}
+
+// Set sets the property called name to value
+func (obj *Dispatch) Set(name string, value interface{}) error {
+	_, err := obj.invoke(name, DISPATCH_PROPERTYPUT, []interface{}{value})
+	return err
+}
+
+func (obj *Dispatch) invoke(name string, flags uint16, args []interface{}) (interface{}, error) {
+	dispID, err := obj.GetIDOfName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// IDispatch::Invoke takes the arguments from last to first
+	vargs := make([]Variant, len(args))
+	defer func() {
+		for i := range vargs {
+			VariantClear(&vargs[i])
+		}
+	}()
+	for i, arg := range args {
+		vargs[len(args)-1-i], err = NewVariant(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var params DispParams
+	if len(vargs) > 0 {
+		params.rgvarg = &vargs[0]
+		params.cArgs = uint32(len(vargs))
+	}
+	if flags&(DISPATCH_PROPERTYPUT|DISPATCH_PROPERTYPUTREF) != 0 {
+		// the value being set has to be passed as a named argument
+		namedArg := int32(DISPID_PROPERTYPUT)
+		params.rgdispidNamedArgs = &namedArg
+		params.cNamedArgs = 1
+	}
+
+	var result Variant
+	var excepInfo ExcepInfo
+	var argErr uint32
+	hr := obj.Invoke(dispID, &IID_NULL, LOCALE_USER_DEFAULT, flags, &params, &result, &excepInfo, &argErr)
+	caller := "dispatch.Invoke(" + name + ")"
+	switch hr {
+	case S_OK:
+	case DISP_E_EXCEPTION:
+		return nil, excepInfo.toError(caller)
+	case DISP_E_TYPEMISMATCH, DISP_E_PARAMNOTFOUND:
+		// argErr is an index into the reversed arguments
+		return nil, fmt.Errorf("%s returned 0x%08x for argument %d", caller, hr, len(args)-1-int(argErr))
+	default:
+		return nil, checkOK(hr, caller)
+	}
+	defer VariantClear(&result)
+	return result.ToGo()
+}
+
+// toError turns the exception described by the ExcepInfo into a *ManagedError and frees its strings
+func (e *ExcepInfo) toError(caller string) error {
+	if e.pfnDeferredFillIn != 0 {
+		syscall.Syscall(e.pfnDeferredFillIn, 1, uintptr(unsafe.Pointer(e)), 0, 0)
+	}
+	managedErr := &ManagedError{
+		Caller:      caller,
+		HResult:     e.scode,
+		Source:      readBSTR(e.bstrSource),
+		Description: readBSTR(e.bstrDescription),
+	}
+	// the help file isn't reported, but it still has to be freed
+	readBSTR(e.bstrHelpFile)
+	if managedErr.HResult == 0 {
+		managedErr.HResult = DISP_E_EXCEPTION
+	}
+	return managedErr
+}
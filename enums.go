@@ -0,0 +1,111 @@
+// +build windows
+
+package clr
+
+// BindingFlags from System.Reflection. Controls how members are searched for and invoked through reflection
+type BindingFlags uint32
+
+const (
+	BindingFlagsDefault              BindingFlags = 0x00000000
+	BindingFlagsIgnoreCase           BindingFlags = 0x00000001
+	BindingFlagsDeclaredOnly         BindingFlags = 0x00000002
+	BindingFlagsInstance             BindingFlags = 0x00000004
+	BindingFlagsStatic               BindingFlags = 0x00000008
+	BindingFlagsPublic               BindingFlags = 0x00000010
+	BindingFlagsNonPublic            BindingFlags = 0x00000020
+	BindingFlagsFlattenHierarchy     BindingFlags = 0x00000040
+	BindingFlagsInvokeMethod         BindingFlags = 0x00000100
+	BindingFlagsCreateInstance       BindingFlags = 0x00000200
+	BindingFlagsGetField             BindingFlags = 0x00000400
+	BindingFlagsSetField             BindingFlags = 0x00000800
+	BindingFlagsGetProperty          BindingFlags = 0x00001000
+	BindingFlagsSetProperty          BindingFlags = 0x00002000
+	BindingFlagsPutDispProperty      BindingFlags = 0x00004000
+	BindingFlagsPutRefDispProperty   BindingFlags = 0x00008000
+	BindingFlagsExactBinding         BindingFlags = 0x00010000
+	BindingFlagsSuppressChangeType   BindingFlags = 0x00020000
+	BindingFlagsOptionalParamBinding BindingFlags = 0x00040000
+	BindingFlagsIgnoreReturn         BindingFlags = 0x01000000
+)
+
+// MethodAttributes from System.Reflection. Describes the accessibility and other characteristics of a method
+type MethodAttributes uint32
+
+const (
+	MethodAttributesMemberAccessMask      MethodAttributes = 0x00000007
+	MethodAttributesPrivateScope          MethodAttributes = 0x00000000
+	MethodAttributesPrivate               MethodAttributes = 0x00000001
+	MethodAttributesFamANDAssem           MethodAttributes = 0x00000002
+	MethodAttributesAssembly              MethodAttributes = 0x00000003
+	MethodAttributesFamily                MethodAttributes = 0x00000004
+	MethodAttributesFamORAssem            MethodAttributes = 0x00000005
+	MethodAttributesPublic                MethodAttributes = 0x00000006
+	MethodAttributesUnmanagedExport       MethodAttributes = 0x00000008
+	MethodAttributesStatic                MethodAttributes = 0x00000010
+	MethodAttributesFinal                 MethodAttributes = 0x00000020
+	MethodAttributesVirtual               MethodAttributes = 0x00000040
+	MethodAttributesHideBySig             MethodAttributes = 0x00000080
+	MethodAttributesVtableLayoutMask      MethodAttributes = 0x00000100
+	MethodAttributesReuseSlot             MethodAttributes = 0x00000000
+	MethodAttributesNewSlot               MethodAttributes = 0x00000100
+	MethodAttributesCheckAccessOnOverride MethodAttributes = 0x00000200
+	MethodAttributesAbstract              MethodAttributes = 0x00000400
+	MethodAttributesSpecialName           MethodAttributes = 0x00000800
+	MethodAttributesRTSpecialName         MethodAttributes = 0x00001000
+	MethodAttributesPinvokeImpl           MethodAttributes = 0x00002000
+	MethodAttributesHasSecurity           MethodAttributes = 0x00004000
+	MethodAttributesRequireSecObject      MethodAttributes = 0x00008000
+	MethodAttributesReservedMask          MethodAttributes = 0x0000d000
+)
+
+// Access returns the accessibility of the method, one of MethodAttributesPrivateScope through MethodAttributesPublic
+func (a MethodAttributes) Access() MethodAttributes {
+	return a & MethodAttributesMemberAccessMask
+}
+
+// CallingConventions from System.Reflection. Describes how a method is called
+type CallingConventions uint32
+
+const (
+	CallingConventionsStandard     CallingConventions = 0x00000001
+	CallingConventionsVarArgs      CallingConventions = 0x00000002
+	CallingConventionsAny          CallingConventions = 0x00000003
+	CallingConventionsHasThis      CallingConventions = 0x00000020
+	CallingConventionsExplicitThis CallingConventions = 0x00000040
+)
+
+// MemberTypes from System.Reflection. Identifies the kind of a member
+type MemberTypes uint32
+
+const (
+	MemberTypesConstructor MemberTypes = 0x00000001
+	MemberTypesEvent       MemberTypes = 0x00000002
+	MemberTypesField       MemberTypes = 0x00000004
+	MemberTypesMethod      MemberTypes = 0x00000008
+	MemberTypesProperty    MemberTypes = 0x00000010
+	MemberTypesTypeInfo    MemberTypes = 0x00000020
+	MemberTypesCustom      MemberTypes = 0x00000040
+	MemberTypesNestedType  MemberTypes = 0x00000080
+	MemberTypesAll         MemberTypes = 0x000000bf
+)
+
+// MethodImplAttributes from System.Reflection. Describes how a method is implemented
+type MethodImplAttributes uint32
+
+const (
+	MethodImplAttributesCodeTypeMask       MethodImplAttributes = 0x00000003
+	MethodImplAttributesIL                 MethodImplAttributes = 0x00000000
+	MethodImplAttributesNative             MethodImplAttributes = 0x00000001
+	MethodImplAttributesOPTIL              MethodImplAttributes = 0x00000002
+	MethodImplAttributesRuntime            MethodImplAttributes = 0x00000003
+	MethodImplAttributesManagedMask        MethodImplAttributes = 0x00000004
+	MethodImplAttributesUnmanaged          MethodImplAttributes = 0x00000004
+	MethodImplAttributesManaged            MethodImplAttributes = 0x00000000
+	MethodImplAttributesNoInlining         MethodImplAttributes = 0x00000008
+	MethodImplAttributesForwardRef         MethodImplAttributes = 0x00000010
+	MethodImplAttributesSynchronized       MethodImplAttributes = 0x00000020
+	MethodImplAttributesNoOptimization     MethodImplAttributes = 0x00000040
+	MethodImplAttributesPreserveSig        MethodImplAttributes = 0x00000080
+	MethodImplAttributesAggressiveInlining MethodImplAttributes = 0x00000100
+	MethodImplAttributesInternalCall       MethodImplAttributes = 0x00001000
+)
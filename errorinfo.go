@@ -0,0 +1,122 @@
+// +build windows
+
+package clr
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// IErrorInfo Interface from oaidl.h. When a call into managed code fails, the CLR leaves one of these on the calling
+// thread describing the exception that was thrown
+type IErrorInfo struct {
+	vtbl *IErrorInfoVtbl
+}
+
+type IErrorInfoVtbl struct {
+	QueryInterface uintptr
+	AddRef         uintptr
+	Release        uintptr
+	GetGUID        uintptr
+	GetSource      uintptr
+	GetDescription uintptr
+	GetHelpFile    uintptr
+	GetHelpContext uintptr
+}
+
+// ManagedError is returned when a call into the CLR failed because managed code threw an exception. It carries the
+// failing HRESULT along with the exception's message and source as reported through IErrorInfo
+type ManagedError struct {
+	Caller      string
+	HResult     uint32
+	Source      string
+	Description string
+}
+
+func (e *ManagedError) Error() string {
+	return fmt.Sprintf("%s returned 0x%08x: %s", e.Caller, e.HResult, e.Description)
+}
+
+// GetErrorInfo is a wrapper for the OleAut32.dll GetErrorInfo syscall. It returns the error information left on the
+// current thread by the last failed COM call, or nil if there is none. The caller must lock the goroutine to its OS
+// thread for the duration of the failed call and this one
+func GetErrorInfo() *IErrorInfo {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	procGetErrorInfo := modOleAuto.MustFindProc("GetErrorInfo")
+	var pErrorInfo uintptr
+	hr, _, _ := procGetErrorInfo.Call(0, uintptr(unsafe.Pointer(&pErrorInfo)))
+	if hr != S_OK || pErrorInfo == 0 {
+		return nil
+	}
+	return NewIErrorInfoFromPtr(pErrorInfo)
+}
+
+func NewIErrorInfoFromPtr(ppv uintptr) *IErrorInfo {
+	return (*IErrorInfo)(unsafe.Pointer(ppv))
+}
+
+func (obj *IErrorInfo) AddRef() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.AddRef,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *IErrorInfo) Release() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Release,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *IErrorInfo) GetSource(pBstrSource *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetSource,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pBstrSource)),
+		0)
+	return ret
+}
+
+func (obj *IErrorInfo) GetDescription(pBstrDescription *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetDescription,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pBstrDescription)),
+		0)
+	return ret
+}
+
+// checkManagedOK works like checkOK, but if the call failed and the CLR left error information on the current thread
+// it returns a *ManagedError describing the managed exception instead of just the HRESULT
+func checkManagedOK(hr uintptr, caller string) error {
+	if hr == S_OK {
+		return nil
+	}
+	errorInfo := GetErrorInfo()
+	if errorInfo == nil {
+		return checkOK(hr, caller)
+	}
+	defer errorInfo.Release()
+	managedErr := &ManagedError{
+		Caller:  caller,
+		HResult: uint32(hr),
+	}
+	var bstr uintptr
+	if errorInfo.GetSource(&bstr) == S_OK {
+		managedErr.Source = readBSTR(bstr)
+	}
+	if errorInfo.GetDescription(&bstr) == S_OK {
+		managedErr.Description = readBSTR(bstr)
+	}
+	return managedErr
+}
@@ -0,0 +1,218 @@
+// +build windows
+
+package clr
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// AssemblyResolver is consulted when the AppDomain can't find an assembly by itself. It receives the display name of
+// the missing assembly (e.g. "Newtonsoft.Json, Version=12.0.0.0, Culture=neutral, PublicKeyToken=30ad4fe6b2a6aeed")
+// and returns its raw bytes, or nil if it doesn't know about it
+type AssemblyResolver func(name string) []byte
+
+// AddAssemblyResolver adds a handler to the AppDomain's AssemblyResolve event that loads missing assemblies from the
+// bytes returned by resolver. This lets assemblies loaded from memory reference other assemblies that are not on disk.
+// Each assembly is only loaded once, later requests for the same name get the already loaded assembly
+func (obj *AppDomain) AddAssemblyResolver(resolver AssemblyResolver) (*EventHandler, error) {
+	builder, err := newDelegateBuilder(obj)
+	if err != nil {
+		return nil, err
+	}
+	defer builder.Release()
+	handlerType, err := builder.typeOf("System.ResolveEventHandler")
+	if err != nil {
+		return nil, err
+	}
+	defer handlerType.Release()
+	assemblyType, err := builder.typeOf("System.Reflection.Assembly")
+	if err != nil {
+		return nil, err
+	}
+	defer assemblyType.Release()
+	argsType, err := builder.typeOf("System.ResolveEventArgs")
+	if err != nil {
+		return nil, err
+	}
+	defer argsType.Release()
+
+	var mu sync.Mutex
+	loaded := make(map[string]*Assembly)
+	id := registerEventHandler(func(args *Object) uintptr {
+		value, err := args.getProperty("Name")
+		name, ok := value.(string)
+		if err != nil || !ok {
+			return 0
+		}
+
+		mu.Lock()
+		assembly := loaded[name]
+		mu.Unlock()
+		if assembly != nil {
+			return uintptr(unsafe.Pointer(assembly))
+		}
+		// loading the assembly can fire AssemblyResolve again for its own references, so no locks can be held here
+		rawAssembly := resolver(name)
+		if len(rawAssembly) == 0 {
+			return 0
+		}
+		safeArrayPtr, err := CreateSafeArray(rawAssembly)
+		if err != nil {
+			return 0
+		}
+		defer SafeArrayDestroy(safeArrayPtr)
+		var pAssembly uintptr
+		if hr := obj.Load_3(uintptr(safeArrayPtr), &pAssembly); hr != S_OK {
+			return 0
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if assembly = loaded[name]; assembly != nil {
+			NewAssemblyFromPtr(pAssembly).Release()
+			return uintptr(unsafe.Pointer(assembly))
+		}
+		loaded[name] = NewAssemblyFromPtr(pAssembly)
+		return pAssembly
+	})
+
+	delegate, err := builder.build(handlerType, argsType, assemblyType, id)
+	if err != nil {
+		unregisterEventHandler(id)
+		return nil, err
+	}
+	hr := obj.AddAssemblyResolve(uintptr(unsafe.Pointer(delegate)))
+	err = checkOK(hr, "appDomain.add_AssemblyResolve")
+	if err != nil {
+		delegate.Release()
+		unregisterEventHandler(id)
+		return nil, err
+	}
+	return &EventHandler{
+		delegate: delegate,
+		id:       id,
+		remove:   obj.RemoveAssemblyResolve,
+		caller:   "appDomain.remove_AssemblyResolve",
+		release: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, assembly := range loaded {
+				assembly.Release()
+			}
+			loaded = nil
+		},
+	}, nil
+}
+
+// UnhandledException describes an exception that managed code never caught, as reported by the AppDomain's
+// UnhandledException event
+type UnhandledException struct {
+	// Type is the full name of the exception's type, e.g. "System.NullReferenceException"
+	Type string
+	// Message is the exception's message
+	Message string
+	// Details is the result of calling ToString on the exception, which includes the stack trace
+	Details string
+	// IsTerminating is true if the CLR is going to terminate the process after the handlers have run
+	IsTerminating bool
+}
+
+// AddUnhandledExceptionHandler adds a handler to the AppDomain's UnhandledException event, which fires when an
+// exception escapes managed code running on any thread, including background threads whose failures never surface
+// through an HRESULT
+func (obj *AppDomain) AddUnhandledExceptionHandler(handler func(e *UnhandledException)) (*EventHandler, error) {
+	return obj.addEventHandler(
+		"UnhandledException",
+		"System.UnhandledExceptionEventHandler",
+		"System.UnhandledExceptionEventArgs",
+		obj.AddUnhandledException,
+		obj.RemoveUnhandledException,
+		func(args *Object) {
+			e := &UnhandledException{}
+			if isTerminating, err := args.getProperty("IsTerminating"); err == nil {
+				e.IsTerminating, _ = isTerminating.(bool)
+			}
+			value, err := args.getProperty("ExceptionObject")
+			if exception, ok := value.(*Object); err == nil && ok {
+				defer exception.Release()
+				e.Details, _ = exception.ToString()
+				if message, err := exception.getProperty("Message"); err == nil {
+					e.Message, _ = message.(string)
+				}
+				if exceptionType, err := exception.GetRuntimeType(); err == nil {
+					e.Type, _ = exceptionType.GetFullName()
+					exceptionType.Release()
+				}
+			}
+			handler(e)
+		})
+}
+
+// AddProcessExitHandler adds a handler to the AppDomain's ProcessExit event, which fires when the CLR shuts down
+// because the process is exiting (e.g. managed code called Environment.Exit)
+func (obj *AppDomain) AddProcessExitHandler(handler func()) (*EventHandler, error) {
+	return obj.addEventHandler(
+		"ProcessExit",
+		"System.EventHandler",
+		"System.EventArgs",
+		obj.AddProcessExit,
+		obj.RemoveProcessExit,
+		func(*Object) { handler() })
+}
+
+// AddDomainUnloadHandler adds a handler to the AppDomain's DomainUnload event, which fires just before the domain is
+// unloaded. The default AppDomain is never unloaded so this event never fires for it
+func (obj *AppDomain) AddDomainUnloadHandler(handler func()) (*EventHandler, error) {
+	return obj.addEventHandler(
+		"DomainUnload",
+		"System.EventHandler",
+		"System.EventArgs",
+		obj.AddDomainUnload,
+		obj.RemoveDomainUnload,
+		func(*Object) { handler() })
+}
+
+// addEventHandler adds a delegate of type handlerTypeName, taking event args of type argsTypeName, to one of the
+// AppDomain's events through add. When the event fires, fn is called with the event args
+func (obj *AppDomain) addEventHandler(event, handlerTypeName, argsTypeName string, add, remove func(value uintptr) uintptr, fn func(args *Object)) (*EventHandler, error) {
+	builder, err := newDelegateBuilder(obj)
+	if err != nil {
+		return nil, err
+	}
+	defer builder.Release()
+	handlerType, err := builder.typeOf(handlerTypeName)
+	if err != nil {
+		return nil, err
+	}
+	defer handlerType.Release()
+	argsType, err := builder.typeOf(argsTypeName)
+	if err != nil {
+		return nil, err
+	}
+	defer argsType.Release()
+
+	id := registerEventHandler(func(args *Object) uintptr {
+		fn(args)
+		return 0
+	})
+
+	delegate, err := builder.build(handlerType, argsType, nil, id)
+	if err != nil {
+		unregisterEventHandler(id)
+		return nil, err
+	}
+	hr := add(uintptr(unsafe.Pointer(delegate)))
+	err = checkOK(hr, "appDomain.add_"+event)
+	if err != nil {
+		delegate.Release()
+		unregisterEventHandler(id)
+		return nil, err
+	}
+	return &EventHandler{
+		delegate: delegate,
+		id:       id,
+		remove:   remove,
+		caller:   "appDomain.remove_" + event,
+	}, nil
+}
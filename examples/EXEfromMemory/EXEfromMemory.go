@@ -75,6 +75,7 @@ func main() {
 	iu := clr.NewIUnknownFromPtr(pIUnknown)
 	hr = iu.QueryInterface(&clr.IID_AppDomain, &pAppDomain)
 	checkOK(hr, "iu.QueryInterface")
+	iu.Release()
 	appDomain := clr.NewAppDomainFromPtr(pAppDomain)
 	fmt.Println("[+] Got default AppDomain")
 
@@ -88,6 +89,7 @@ func main() {
 	var pAssembly uintptr
 	hr = appDomain.Load_3(uintptr(unsafe.Pointer(&safeArray)), &pAssembly)
 	checkOK(hr, "appDomain.Load_3")
+	clr.SafeArrayDestroy(safeArray)
 	assembly := clr.NewAssemblyFromPtr(pAssembly)
 	fmt.Printf("[+] Executable loaded into memory at 0x%08x\n", pAssembly)
 
@@ -98,21 +100,19 @@ func main() {
 	fmt.Println("-------")
 	methodInfo := clr.NewMethodInfoFromPtr(pEntryPointInfo)
 
-	var pRetCode uintptr
-	nullVariant := clr.Variant{
-		VT:  1,
-		Val: uintptr(0),
-	}
+	var retCode clr.Variant
 	hr = methodInfo.Invoke_3(
-		nullVariant,
+		clr.NewVariantNull(),
 		uintptr(0),
-		&pRetCode)
+		&retCode)
 
 	fmt.Println("-------")
 
 	checkOK(hr, "methodInfo.Invoke_3")
-	fmt.Printf("[+] Executable returned code %d\n", pRetCode)
+	fmt.Printf("[+] Executable returned code %d\n", int32(retCode.Val))
 
+	methodInfo.Release()
+	assembly.Release()
 	appDomain.Release()
 	runtimeHost.Release()
 	runtimeInfo.Release()
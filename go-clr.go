@@ -21,19 +21,21 @@ func GetInstalledRuntimes(metahost *ICLRMetaHost) ([]string, error) {
 		return runtimes, err
 	}
 	installedRuntimes := NewIEnumUnknownFromPtr(pInstalledRuntimes)
+	defer installedRuntimes.Release()
 	var pRuntimeInfo uintptr
 	var fetched = uint32(0)
 	var versionString string
 	versionStringBytes := make([]uint16, 20)
 	versionStringSize := uint32(len(versionStringBytes))
-	var runtimeInfo *ICLRRuntimeInfo
 	for {
 		hr = installedRuntimes.Next(1, &pRuntimeInfo, &fetched)
 		if hr != S_OK {
 			break
 		}
-		runtimeInfo = NewICLRRuntimeInfoFromPtr(pRuntimeInfo)
-		if ret := runtimeInfo.GetVersionString(&versionStringBytes[0], &versionStringSize); ret != S_OK {
+		runtimeInfo := NewICLRRuntimeInfoFromPtr(pRuntimeInfo)
+		ret := runtimeInfo.GetVersionString(&versionStringBytes[0], &versionStringSize)
+		runtimeInfo.Release()
+		if ret != S_OK {
 			return runtimes, fmt.Errorf("GetVersionString returned 0x%08x", ret)
 		}
 		versionString = syscall.UTF16ToString(versionStringBytes)
@@ -42,7 +44,6 @@ func GetInstalledRuntimes(metahost *ICLRMetaHost) ([]string, error) {
 	if len(runtimes) == 0 {
 		return runtimes, fmt.Errorf("Could not find any installed runtimes")
 	}
-	runtimeInfo.Release()
 	return runtimes, err
 }
 
@@ -58,6 +59,7 @@ func ExecuteDLLFromDisk(targetRuntime, dllpath, typeName, methodName, argument s
 	if err != nil {
 		return
 	}
+	defer metahost.Release()
 
 	runtimes, err := GetInstalledRuntimes(metahost)
 	if err != nil {
@@ -76,6 +78,7 @@ func ExecuteDLLFromDisk(targetRuntime, dllpath, typeName, methodName, argument s
 	if err != nil {
 		return
 	}
+	defer runtimeInfo.Release()
 	var isLoadable bool
 	hr := runtimeInfo.IsLoadable(&isLoadable)
 	err = checkOK(hr, "runtimeInfo.IsLoadable")
@@ -89,6 +92,7 @@ func ExecuteDLLFromDisk(targetRuntime, dllpath, typeName, methodName, argument s
 	if err != nil {
 		return
 	}
+	defer runtimeHost.Release()
 
 	pDLLPath, _ := syscall.UTF16PtrFromString(dllpath)
 	pTypeName, _ := syscall.UTF16PtrFromString(typeName)
@@ -100,9 +104,6 @@ func ExecuteDLLFromDisk(targetRuntime, dllpath, typeName, methodName, argument s
 	if err != nil {
 		return int16(pReturnVal), err
 	}
-	runtimeHost.Release()
-	runtimeInfo.Release()
-	metahost.Release()
 	return int16(pReturnVal), nil
 
 }
@@ -112,6 +113,16 @@ func ExecuteDLLFromDisk(targetRuntime, dllpath, typeName, methodName, argument s
 // will default to latest. It takes in a byte array of the executable to load and run and returns the return code.
 // You can supply an array of strings as command line arguments.
 func ExecuteByteArray(targetRuntime string, rawBytes []byte, params []string) (retCode int32, err error) {
+	return executeByteArray(targetRuntime, rawBytes, nil, params)
+}
+
+// ExecuteByteArrayWithSymbols works like ExecuteByteArray, but also loads the debug symbols (the raw bytes of the
+// assembly's PDB) alongside the executable so stack traces from the in-memory code include file names and line numbers.
+func ExecuteByteArrayWithSymbols(targetRuntime string, rawBytes, rawSymbols []byte, params []string) (retCode int32, err error) {
+	return executeByteArray(targetRuntime, rawBytes, rawSymbols, params)
+}
+
+func executeByteArray(targetRuntime string, rawBytes, rawSymbols []byte, params []string) (retCode int32, err error) {
 	retCode = -1
 	if targetRuntime == "" {
 		targetRuntime = "v4"
@@ -120,6 +131,7 @@ func ExecuteByteArray(targetRuntime string, rawBytes []byte, params []string) (r
 	if err != nil {
 		return
 	}
+	defer metahost.Release()
 
 	runtimes, err := GetInstalledRuntimes(metahost)
 	if err != nil {
@@ -138,6 +150,7 @@ func ExecuteByteArray(targetRuntime string, rawBytes []byte, params []string) (r
 	if err != nil {
 		return
 	}
+	defer runtimeInfo.Release()
 	var isLoadable bool
 	hr := runtimeInfo.IsLoadable(&isLoadable)
 	err = checkOK(hr, "runtimeInfo.IsLoadable")
@@ -151,21 +164,22 @@ func ExecuteByteArray(targetRuntime string, rawBytes []byte, params []string) (r
 	if err != nil {
 		return
 	}
+	defer runtimeHost.Release()
 	appDomain, err := GetAppDomain(runtimeHost)
 	if err != nil {
 		return
 	}
-	safeArrayPtr, err := CreateSafeArray(rawBytes)
-	if err != nil {
-		return
+	defer appDomain.Release()
+	var assembly *Assembly
+	if rawSymbols != nil {
+		assembly, err = appDomain.LoadAssemblyWithSymbols(rawBytes, rawSymbols)
+	} else {
+		assembly, err = appDomain.LoadAssembly(rawBytes)
 	}
-	var pAssembly uintptr
-	hr = appDomain.Load_3(uintptr(safeArrayPtr), &pAssembly)
-	err = checkOK(hr, "appDomain.Load_3")
 	if err != nil {
 		return
 	}
-	assembly := NewAssemblyFromPtr(pAssembly)
+	defer assembly.Release()
 	var pEntryPointInfo uintptr
 	hr = assembly.GetEntryPoint(&pEntryPointInfo)
 	err = checkOK(hr, "assembly.GetEntryPoint")
@@ -173,64 +187,64 @@ func ExecuteByteArray(targetRuntime string, rawBytes []byte, params []string) (r
 		return
 	}
 	methodInfo := NewMethodInfoFromPtr(pEntryPointInfo)
+	defer methodInfo.Release()
 
-	var methodSignaturePtr, paramPtr uintptr
-	err = methodInfo.GetString(&methodSignaturePtr)
+	parameters, err := methodInfo.Parameters()
 	if err != nil {
 		return
 	}
-	methodSignature := readUnicodeStr(unsafe.Pointer(methodSignaturePtr))
+	for _, parameter := range parameters {
+		parameter.Release()
+	}
 
-	if expectsParams(methodSignature) {
+	// Main either takes no parameters or a single string[] with the command line arguments
+	var paramPtr uintptr
+	if len(parameters) > 0 {
 		if paramPtr, err = PrepareParameters(params); err != nil {
 			return
 		}
+		defer SafeArrayDestroy(unsafe.Pointer(paramPtr))
 	}
 
-	var pRetCode uintptr
-	nullVariant := Variant{
-		VT:  1,
-		Val: uintptr(0),
-	}
+	var ret Variant
 	hr = methodInfo.Invoke_3(
-		nullVariant,
+		NewVariantNull(),
 		paramPtr,
-		&pRetCode)
+		&ret)
 	err = checkOK(hr, "methodInfo.Invoke_3")
 	if err != nil {
 		return
 	}
-	appDomain.Release()
-	runtimeHost.Release()
-	runtimeInfo.Release()
-	metahost.Release()
-	return int32(pRetCode), nil
+	defer ret.Clear()
+	// Main either returns an int or is void, in which case the exit code is 0
+	retCode = 0
+	if ret.VT == VT_I4 {
+		retCode = int32(ret.Val)
+	}
+	return retCode, nil
 
 }
 
 // PrepareParameters creates a safe array of strings (arguments) nested inside a Variant object, which is itself
-// appended to the final safe array
+// appended to the final safe array. The caller owns the returned array and must free it with SafeArrayDestroy, which
+// also frees the nested array and its strings
 func PrepareParameters(params []string) (uintptr, error) {
-	listStrSafeArrayPtr, err := CreateEmptySafeArray(0x0008, len(params)) // VT_BSTR
+	listStrSafeArrayPtr, err := CreateStringSafeArray(params)
 	if err != nil {
 		return 0, err
 	}
-	for i, p := range params {
-		bstr, _ := SysAllocString(p)
-		SafeArrayPutElement(listStrSafeArrayPtr, bstr, i)
-	}
 
-	paramVariant := Variant{
-		VT:  0x0008 | 0x2000, // VT_BSTR | VT_ARRAY
-		Val: uintptr(listStrSafeArrayPtr),
-	}
+	paramVariant := NewVariantArray(VT_BSTR, listStrSafeArrayPtr)
+	// SafeArrayPutElement stores its own copy of the nested array, so ours is always freed
+	defer paramVariant.Clear()
 
-	paramsSafeArrayPtr, err := CreateEmptySafeArray(0x000C, 1) // VT_VARIANT
+	paramsSafeArrayPtr, err := CreateEmptySafeArray(VT_VARIANT, 1)
 	if err != nil {
 		return 0, err
 	}
 	err = SafeArrayPutElement(paramsSafeArrayPtr, unsafe.Pointer(&paramVariant), 0)
 	if err != nil {
+		SafeArrayDestroy(paramsSafeArrayPtr)
 		return 0, err
 	}
 	return uintptr(paramsSafeArrayPtr), nil
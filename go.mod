@@ -1,8 +1,8 @@
 module github.com/ropnop/go-clr
 
-go 1.13
+go 1.17
 
 require (
-	golang.org/x/sys v0.0.0-20200302150141-5c8b2ff67527
+	golang.org/x/sys v0.7.0
 	golang.org/x/text v0.3.3
 )
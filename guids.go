@@ -17,5 +17,13 @@ var (
 	IID_ICorRuntimeHost = windows.GUID{0xcb2f6722, 0xab3a, 0x11d2, [8]byte{0x9c, 0x40, 0x00, 0xc0, 0x4f, 0xa3, 0x0a, 0x3e}}
 	CLSID_CorRuntimeHost = windows.GUID{0xcb2f6723, 0xab3a, 0x11d2, [8]byte{0x9c, 0x40, 0x00, 0xc0, 0x4f, 0xa3, 0x0a, 0x3e}}
 
-	IID_AppDomain = windows.GUID{0x5f696dc, 0x2b29, 0x3663, [8]uint8{0xad, 0x8b, 0xc4, 0x38, 0x9c, 0xf2, 0xa7, 0x13}}
+	IID_AppDomain     = windows.GUID{0x5f696dc, 0x2b29, 0x3663, [8]uint8{0xad, 0x8b, 0xc4, 0x38, 0x9c, 0xf2, 0xa7, 0x13}}
+	IID_Assembly      = windows.GUID{0x17156360, 0x2f1a, 0x384a, [8]uint8{0xbc, 0x52, 0xfd, 0xe9, 0x3c, 0x21, 0x5c, 0x5b}}
+	IID_Object        = windows.GUID{0x65074f7f, 0x63c0, 0x304e, [8]uint8{0xaf, 0x0a, 0xd5, 0x17, 0x41, 0xcb, 0x4a, 0x8d}}
+	IID_Type          = windows.GUID{0xbca8b44d, 0xaad6, 0x3a86, [8]uint8{0x8a, 0xb7, 0x03, 0x34, 0x9f, 0x4f, 0x2d, 0xa2}}
+	IID_MethodInfo    = windows.GUID{0xffcc1b5d, 0xecb8, 0x38dd, [8]uint8{0x9b, 0x01, 0x3d, 0xc8, 0xab, 0xc2, 0xaa, 0x5f}}
+	IID_ParameterInfo = windows.GUID{0x993634c4, 0xe47a, 0x32cc, [8]uint8{0xbe, 0x08, 0x85, 0xf5, 0x67, 0xdc, 0x27, 0xd6}}
+
+	IID_NULL      = windows.GUID{}
+	IID_IDispatch = windows.GUID{0x00020400, 0x0000, 0x0000, [8]uint8{0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
 )
@@ -49,7 +49,11 @@ func GetICORRuntimeHost(runtimeInfo *ICLRRuntimeInfo) (*ICORRuntimeHost, error)
 	runtimeHost := NewICORRuntimeHostFromPtr(pRuntimeHost)
 	hr = runtimeHost.Start()
 	err = checkOK(hr, "runtimeHost.Start")
-	return runtimeHost, err
+	if err != nil {
+		runtimeHost.Release()
+		return nil, err
+	}
+	return runtimeHost, nil
 }
 
 func NewICORRuntimeHostFromPtr(ppv uintptr) *ICORRuntimeHost {
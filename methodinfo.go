@@ -3,6 +3,8 @@
 package clr
 
 import (
+	"fmt"
+	"runtime"
 	"syscall"
 	"unsafe"
 
@@ -103,7 +105,23 @@ func (obj *MethodInfo) GetType(pRetVal *uintptr) uintptr {
 	return ret
 }
 
-func (obj *MethodInfo) Invoke_3(variantObj Variant, parameters uintptr, pRetVal *uintptr) uintptr {
+func (obj *MethodInfo) Invoke_3(variantObj Variant, parameters uintptr, pRetVal *Variant) uintptr {
+	if variantByValue {
+		v := variantObj.words()
+		ret, _, _ := syscall.Syscall9(
+			obj.vtbl.Invoke_3,
+			7,
+			uintptr(unsafe.Pointer(obj)),
+			v[0],
+			v[1],
+			v[2],
+			v[3],
+			parameters,
+			uintptr(unsafe.Pointer(pRetVal)),
+			0,
+			0)
+		return ret
+	}
 	ret, _, _ := syscall.Syscall6(
 		obj.vtbl.Invoke_3,
 		4,
@@ -117,6 +135,72 @@ func (obj *MethodInfo) Invoke_3(variantObj Variant, parameters uintptr, pRetVal
 	return ret
 }
 
+func (obj *MethodInfo) GetParameters(pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetParameters,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+// Parameters returns the parameters of the method in the order they appear in its signature. The caller owns a
+// reference to each ParameterInfo returned
+func (obj *MethodInfo) Parameters() ([]*ParameterInfo, error) {
+	var pParameters uintptr
+	hr := obj.GetParameters(&pParameters)
+	err := checkOK(hr, "methodInfo.GetParameters")
+	if err != nil {
+		return nil, err
+	}
+	ptrs, err := safeArrayInterfaces(unsafe.Pointer(pParameters), &IID_ParameterInfo)
+	parameters := make([]*ParameterInfo, len(ptrs))
+	for i, ptr := range ptrs {
+		parameters[i] = NewParameterInfoFromPtr(ptr)
+	}
+	return parameters, err
+}
+
+// Invoke calls the static method described by the MethodInfo. The args are converted with NewVariant and the VARIANT
+// the method returns is converted with ToGo, so the result is an int32, string, bool, []byte, *Object or nil for void
+// methods. If the method throws, the error is a *ManagedError describing the exception
+func (obj *MethodInfo) Invoke(args ...interface{}) (interface{}, error) {
+	return obj.InvokeInstance(nil, args...)
+}
+
+// InvokeInstance calls the instance method described by the MethodInfo on target, e.g. an object created with
+// AppDomain.CreateObject or returned by another method. The target stays owned by the caller, so the same object can
+// be used for any number of calls. A nil target calls a static method. Arguments and results are converted like Invoke
+func (obj *MethodInfo) InvokeInstance(target *Object, args ...interface{}) (interface{}, error) {
+	variantObj := Variant{VT: VT_EMPTY}
+	if target != nil {
+		var err error
+		// NewVariant AddRefs the object and VariantClear releases it again
+		variantObj, err = NewVariant(target)
+		if err != nil {
+			return nil, err
+		}
+		defer VariantClear(&variantObj)
+	}
+	pArgs, err := CreateVariantSafeArray(args)
+	if err != nil {
+		return nil, err
+	}
+	defer SafeArrayDestroy(pArgs)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	var ret Variant
+	hr := obj.Invoke_3(variantObj, uintptr(pArgs), &ret)
+	err = checkManagedOK(hr, "methodInfo.Invoke_3")
+	if err != nil {
+		return nil, err
+	}
+	defer VariantClear(&ret)
+	return ret.ToGo()
+}
+
 // GetString returns a string version of the method's signature
 func (obj *MethodInfo) GetString(addr *uintptr) error {
 	ret, _, _ := syscall.Syscall(
@@ -128,3 +212,314 @@ func (obj *MethodInfo) GetString(addr *uintptr) error {
 	)
 	return checkOK(ret, "get_ToString")
 }
+
+func (obj *MethodInfo) GetCustomAttributes(attributeType uintptr, inherit int16, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.GetCustomAttributes,
+		4,
+		uintptr(unsafe.Pointer(obj)),
+		attributeType,
+		uintptr(inherit),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *MethodInfo) GetCustomAttributes_2(inherit int16, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetCustomAttributes_2,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(inherit),
+		uintptr(unsafe.Pointer(pRetVal)))
+	return ret
+}
+
+// CustomAttributes returns the custom attributes applied to the method. If attributeType is not nil, only attributes
+// of that type (or derived from it) are returned. If inherit is true the attributes of the methods it overrides are
+// included too
+func (obj *MethodInfo) CustomAttributes(attributeType *Type, inherit bool) ([]*Object, error) {
+	var vInherit int16 // VARIANT_BOOL
+	if inherit {
+		vInherit = -1
+	}
+	var pAttributes uintptr
+	var hr uintptr
+	if attributeType != nil {
+		hr = obj.GetCustomAttributes(uintptr(unsafe.Pointer(attributeType)), vInherit, &pAttributes)
+	} else {
+		hr = obj.GetCustomAttributes_2(vInherit, &pAttributes)
+	}
+	err := checkOK(hr, "methodInfo.GetCustomAttributes")
+	if err != nil {
+		return nil, err
+	}
+	return objectsFromSafeArray(pAttributes)
+}
+
+// IsDefined returns whether one or more attributes of attributeType are applied to the method
+func (obj *MethodInfo) IsDefined(attributeType *Type, inherit bool) (bool, error) {
+	var vInherit int16 // VARIANT_BOOL
+	if inherit {
+		vInherit = -1
+	}
+	var value int16 // VARIANT_BOOL
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.IsDefined,
+		4,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(attributeType)),
+		uintptr(vInherit),
+		uintptr(unsafe.Pointer(&value)),
+		0,
+		0)
+	if err := checkOK(ret, "methodInfo.IsDefined"); err != nil {
+		return false, err
+	}
+	return value != 0, nil
+}
+
+// GetName returns the name of the method, e.g. "Main"
+func (obj *MethodInfo) GetName() (string, error) {
+	var bstr uintptr
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.get_name,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if err := checkOK(ret, "methodInfo.get_name"); err != nil {
+		return "", err
+	}
+	return readBSTR(bstr), nil
+}
+
+// GetMemberType returns the kind of member, MemberTypesMethod for methods and MemberTypesConstructor for constructors
+func (obj *MethodInfo) GetMemberType() (MemberTypes, error) {
+	value, err := obj.getUint32(obj.vtbl.get_MemberType, "methodInfo.get_MemberType")
+	return MemberTypes(value), err
+}
+
+// GetAttributes returns the attributes of the method, such as its accessibility and whether it is static or virtual
+func (obj *MethodInfo) GetAttributes() (MethodAttributes, error) {
+	value, err := obj.getUint32(obj.vtbl.get_Attributes, "methodInfo.get_Attributes")
+	return MethodAttributes(value), err
+}
+
+// GetCallingConvention returns the calling convention of the method
+func (obj *MethodInfo) GetCallingConvention() (CallingConventions, error) {
+	value, err := obj.getUint32(obj.vtbl.get_CallingConvention, "methodInfo.get_CallingConvention")
+	return CallingConventions(value), err
+}
+
+// GetMethodImplementationFlags returns how the method is implemented, e.g. whether it is IL or an internal call
+func (obj *MethodInfo) GetMethodImplementationFlags() (MethodImplAttributes, error) {
+	value, err := obj.getUint32(obj.vtbl.GetMethodImplementationFlags, "methodInfo.GetMethodImplementationFlags")
+	return MethodImplAttributes(value), err
+}
+
+// GetReturnType returns the type the method returns, which is System.Void for methods that don't return anything
+func (obj *MethodInfo) GetReturnType() (*Type, error) {
+	return obj.getType(obj.vtbl.get_returnType, "methodInfo.get_returnType")
+}
+
+// GetDeclaringType returns the type that declares the method
+func (obj *MethodInfo) GetDeclaringType() (*Type, error) {
+	return obj.getType(obj.vtbl.get_DeclaringType, "methodInfo.get_DeclaringType")
+}
+
+// GetReflectedType returns the type the MethodInfo was obtained from, which is a derived type of the declaring type
+// for inherited methods
+func (obj *MethodInfo) GetReflectedType() (*Type, error) {
+	return obj.getType(obj.vtbl.get_ReflectedType, "methodInfo.get_ReflectedType")
+}
+
+// GetBaseDefinition returns the method first declared in the inheritance chain that this method overrides, or the
+// method itself if it doesn't override anything
+func (obj *MethodInfo) GetBaseDefinition() (*MethodInfo, error) {
+	var pMethodInfo uintptr
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetBaseDefinition,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&pMethodInfo)),
+		0)
+	if err := checkOK(ret, "methodInfo.GetBaseDefinition"); err != nil {
+		return nil, err
+	}
+	return NewMethodInfoFromPtr(pMethodInfo), nil
+}
+
+// IsPublic returns whether the method is public
+func (obj *MethodInfo) IsPublic() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsPublic, "methodInfo.get_IsPublic")
+}
+
+// IsPrivate returns whether the method is private
+func (obj *MethodInfo) IsPrivate() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsPrivate, "methodInfo.get_IsPrivate")
+}
+
+// IsFamily returns whether the method is protected
+func (obj *MethodInfo) IsFamily() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsFamily, "methodInfo.get_IsFamily")
+}
+
+// IsAssembly returns whether the method is internal
+func (obj *MethodInfo) IsAssembly() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsAssembly, "methodInfo.get_IsAssembly")
+}
+
+// IsFamilyAndAssembly returns whether the method is private protected
+func (obj *MethodInfo) IsFamilyAndAssembly() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsFamilyAndAssembly, "methodInfo.get_IsFamilyAndAssembly")
+}
+
+// IsFamilyOrAssembly returns whether the method is protected internal
+func (obj *MethodInfo) IsFamilyOrAssembly() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsFamilyOrAssembly, "methodInfo.get_IsFamilyOrAssembly")
+}
+
+// IsStatic returns whether the method is static
+func (obj *MethodInfo) IsStatic() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsStatic, "methodInfo.get_IsStatic")
+}
+
+// IsFinal returns whether the method is sealed and can't be overridden
+func (obj *MethodInfo) IsFinal() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsFinal, "methodInfo.get_IsFinal")
+}
+
+// IsVirtual returns whether the method is virtual
+func (obj *MethodInfo) IsVirtual() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsVirtual, "methodInfo.get_IsVirtual")
+}
+
+// IsHideBySig returns whether the method only hides methods of the base type with the same signature
+func (obj *MethodInfo) IsHideBySig() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsHideBySig, "methodInfo.get_IsHideBySig")
+}
+
+// IsAbstract returns whether the method is abstract
+func (obj *MethodInfo) IsAbstract() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsAbstract, "methodInfo.get_IsAbstract")
+}
+
+// IsSpecialName returns whether the method has a special name, like property accessors and operators
+func (obj *MethodInfo) IsSpecialName() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsSpecialName, "methodInfo.get_IsSpecialName")
+}
+
+// IsConstructor returns whether the method is a constructor
+func (obj *MethodInfo) IsConstructor() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsConstructor, "methodInfo.get_IsConstructor")
+}
+
+// getBool calls one of the method's methods that returns a VARIANT_BOOL
+func (obj *MethodInfo) getBool(method uintptr, caller string) (bool, error) {
+	var value int16 // VARIANT_BOOL
+	ret, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if err := checkOK(ret, caller); err != nil {
+		return false, err
+	}
+	return value != 0, nil
+}
+
+// getUint32 calls one of the method's methods that returns an enum
+func (obj *MethodInfo) getUint32(method uintptr, caller string) (uint32, error) {
+	var value uint32
+	ret, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if err := checkOK(ret, caller); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// getType calls one of the method's methods that returns a _Type
+func (obj *MethodInfo) getType(method uintptr, caller string) (*Type, error) {
+	var pType uintptr
+	ret, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&pType)),
+		0)
+	if err := checkOK(ret, caller); err != nil {
+		return nil, err
+	}
+	if pType == 0 {
+		return nil, nil
+	}
+	return NewTypeFromPtr(pType), nil
+}
+
+// IsGenericMethodDefinition returns whether the method is a generic method definition such as Deserialize<T>, which
+// has to be closed with MakeGenericMethod before it can be invoked
+func (obj *MethodInfo) IsGenericMethodDefinition() (bool, error) {
+	value, err := obj.invokeMember("IsGenericMethodDefinition", BindingFlagsGetProperty|BindingFlagsInstance|BindingFlagsPublic)
+	if err != nil {
+		return false, err
+	}
+	isDefinition, _ := value.(bool)
+	return isDefinition, nil
+}
+
+// IsGenericMethod returns whether the method is generic, either a generic method definition or a closed one
+func (obj *MethodInfo) IsGenericMethod() (bool, error) {
+	value, err := obj.invokeMember("IsGenericMethod", BindingFlagsGetProperty|BindingFlagsInstance|BindingFlagsPublic)
+	if err != nil {
+		return false, err
+	}
+	isGeneric, _ := value.(bool)
+	return isGeneric, nil
+}
+
+// MakeGenericMethod closes a generic method definition over typeArguments, in the order the type parameters are
+// declared, and returns the resulting method that can be invoked
+func (obj *MethodInfo) MakeGenericMethod(typeArguments ...*Type) (*MethodInfo, error) {
+	args := make([]interface{}, len(typeArguments))
+	for i, t := range typeArguments {
+		args[i] = t
+	}
+	value, err := obj.invokeMember("MakeGenericMethod", BindingFlagsInvokeMethod|BindingFlagsInstance|BindingFlagsPublic, args...)
+	if err != nil {
+		return nil, err
+	}
+	method, ok := value.(*Object)
+	if !ok {
+		return nil, fmt.Errorf("MakeGenericMethod returned %T instead of a MethodInfo", value)
+	}
+	defer method.Release()
+	var pMethodInfo uintptr
+	hr := method.QueryInterface(&IID_MethodInfo, &pMethodInfo)
+	err = checkOK(hr, "object.QueryInterface")
+	if err != nil {
+		return nil, err
+	}
+	return NewMethodInfoFromPtr(pMethodInfo), nil
+}
+
+// invokeMember invokes one of the members of System.Reflection.MethodInfo that _MethodInfo doesn't have a slot for
+// through reflection
+func (obj *MethodInfo) invokeMember(name string, invokeAttr BindingFlags, args ...interface{}) (interface{}, error) {
+	var pObject uintptr
+	hr := obj.QueryInterface(&IID_Object, &pObject)
+	err := checkOK(hr, "methodInfo.QueryInterface")
+	if err != nil {
+		return nil, err
+	}
+	o := NewObjectFromPtr(pObject)
+	defer o.Release()
+	return o.InvokeMember(name, invokeAttr, args...)
+}
@@ -0,0 +1,181 @@
+// +build windows
+
+package clr
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// from mscorlib.tlh
+
+// Object is a generic handle to a managed object, through the _Object interface that every managed object exposes
+type Object struct {
+	vtbl *ObjectVtbl
+}
+
+type ObjectVtbl struct {
+	QueryInterface   uintptr
+	AddRef           uintptr
+	Release          uintptr
+	GetTypeInfoCount uintptr
+	GetTypeInfo      uintptr
+	GetIDsOfNames    uintptr
+	Invoke           uintptr
+	get_ToString     uintptr
+	Equals           uintptr
+	GetHashCode      uintptr
+	GetType          uintptr
+}
+
+func NewObjectFromPtr(ppv uintptr) *Object {
+	return (*Object)(unsafe.Pointer(ppv))
+}
+
+// NewObjectFromVariant returns a handle to the managed object held by a VT_UNKNOWN or VT_DISPATCH Variant, such as
+// the ones returned by ObjectHandle.Unwrap. The Variant is left untouched, the returned Object holds its own reference
+func NewObjectFromVariant(v *Variant) (*Object, error) {
+	if (v.VT != VT_UNKNOWN && v.VT != VT_DISPATCH) || v.Val == 0 {
+		return nil, fmt.Errorf("a Variant of type 0x%04x does not hold a managed object", v.VT)
+	}
+	return newObjectFromUnknown(NewIUnknownFromPtr(v.Val))
+}
+
+// newObjectFromUnknown returns a handle to the managed object behind any of its COM interfaces
+func newObjectFromUnknown(iu *IUnknown) (*Object, error) {
+	var pObject uintptr
+	hr := iu.QueryInterface(&IID_Object, &pObject)
+	err := checkOK(hr, "IUnknown.QueryInterface")
+	if err != nil {
+		return nil, err
+	}
+	return NewObjectFromPtr(pObject), nil
+}
+
+func (obj *Object) QueryInterface(riid *windows.GUID, ppvObject *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.QueryInterface,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppvObject)))
+	return ret
+}
+
+func (obj *Object) AddRef() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.AddRef,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *Object) Release() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Release,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *Object) GetType(pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetType,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+// ToString returns the result of calling the managed object's ToString method
+func (obj *Object) ToString() (string, error) {
+	var bstr uintptr
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.get_ToString,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if err := checkOK(ret, "object.get_ToString"); err != nil {
+		return "", err
+	}
+	return readBSTR(bstr), nil
+}
+
+// GetRuntimeType returns the managed System.Type of the object
+func (obj *Object) GetRuntimeType() (*Type, error) {
+	var pType uintptr
+	hr := obj.GetType(&pType)
+	err := checkOK(hr, "object.GetType")
+	if err != nil {
+		return nil, err
+	}
+	return NewTypeFromPtr(pType), nil
+}
+
+// InvokeMember invokes the member called name on the managed object through reflection, looking it up on the object's
+// runtime type. See Type.InvokeMember for how invokeAttr and args are used
+func (obj *Object) InvokeMember(name string, invokeAttr BindingFlags, args ...interface{}) (interface{}, error) {
+	objType, err := obj.GetRuntimeType()
+	if err != nil {
+		return nil, err
+	}
+	defer objType.Release()
+	return objType.InvokeMember(name, invokeAttr, obj, args...)
+}
+
+// CallMethod calls the public instance method called name on the managed object. The overload is picked from the
+// types of args, which are converted like MethodInfo.Invoke
+func (obj *Object) CallMethod(name string, args ...interface{}) (interface{}, error) {
+	return obj.InvokeMember(name, BindingFlagsInvokeMethod|BindingFlagsInstance|BindingFlagsPublic, args...)
+}
+
+// GetDispatch returns the IDispatch interface of the managed object, which reaches all of its public members if its
+// class is COM-visible. The caller owns the returned reference
+func (obj *Object) GetDispatch() (*Dispatch, error) {
+	var pDispatch uintptr
+	hr := obj.QueryInterface(&IID_IDispatch, &pDispatch)
+	err := checkOK(hr, "object.QueryInterface")
+	if err != nil {
+		return nil, err
+	}
+	return NewDispatchFromPtr(pDispatch), nil
+}
+
+// getProperty reads a public instance property of the managed object
+func (obj *Object) getProperty(name string) (interface{}, error) {
+	return obj.InvokeMember(name, BindingFlagsGetProperty|BindingFlagsInstance|BindingFlagsPublic)
+}
+
+// objectsFromSafeArray turns the SAFEARRAY(VARIANT) returned for object[] by methods such as GetCustomAttributes into
+// Objects and destroys it. Elements that aren't managed objects are skipped
+func objectsFromSafeArray(pArray uintptr) ([]*Object, error) {
+	defer SafeArrayDestroy(unsafe.Pointer(pArray))
+	variants, err := SafeArrayToVariants(unsafe.Pointer(pArray))
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]*Object, 0, len(variants))
+	for i := range variants {
+		v := &variants[i]
+		if v.VT == VT_UNKNOWN || v.VT == VT_DISPATCH {
+			object, e := NewObjectFromVariant(v)
+			if e != nil {
+				// keep going so we hold on to the ones that did work and the caller can release them
+				err = e
+			} else {
+				objects = append(objects, object)
+			}
+		}
+		VariantClear(v)
+	}
+	return objects, err
+}
@@ -0,0 +1,82 @@
+// +build windows
+
+package clr
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// from mscorlib.tlh
+
+// ObjectHandle wraps the _ObjectHandle interface returned by the AppDomain.CreateInstance family of methods
+type ObjectHandle struct {
+	vtbl *ObjectHandleVtbl
+}
+
+type ObjectHandleVtbl struct {
+	QueryInterface            uintptr
+	AddRef                    uintptr
+	Release                   uintptr
+	GetTypeInfoCount          uintptr
+	GetTypeInfo               uintptr
+	GetIDsOfNames             uintptr
+	Invoke                    uintptr
+	get_ToString              uintptr
+	Equals                    uintptr
+	GetHashCode               uintptr
+	GetType                   uintptr
+	GetLifetimeService        uintptr
+	InitializeLifetimeService uintptr
+	CreateObjRef              uintptr
+	Unwrap                    uintptr
+}
+
+func NewObjectHandleFromPtr(ppv uintptr) *ObjectHandle {
+	return (*ObjectHandle)(unsafe.Pointer(ppv))
+}
+
+func (obj *ObjectHandle) AddRef() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.AddRef,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *ObjectHandle) Release() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Release,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *ObjectHandle) Unwrap(pRetVal *Variant) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Unwrap,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0)
+	return ret
+}
+
+// unwrapObjectHandle unwraps the object held by an _ObjectHandle returned from one of the CreateInstance methods and
+// releases the handle
+func unwrapObjectHandle(pObjectHandle uintptr) (*Object, error) {
+	objectHandle := NewObjectHandleFromPtr(pObjectHandle)
+	defer objectHandle.Release()
+	var v Variant
+	hr := objectHandle.Unwrap(&v)
+	err := checkOK(hr, "objectHandle.Unwrap")
+	if err != nil {
+		return nil, err
+	}
+	defer VariantClear(&v)
+	return NewObjectFromVariant(&v)
+}
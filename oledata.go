@@ -0,0 +1,169 @@
+package clr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Conversions between Go values and the OLE Automation DATE, CY and DECIMAL formats that managed DateTime, Currency
+// and decimal values are marshaled as
+
+const msPerDay = 24 * 60 * 60 * 1000
+
+// oleEpoch is day 0 of an OLE Automation date
+var oleEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// the range of DATEs accepted by VariantTimeToSystemTime, 0100-01-01 to 9999-12-31 23:59:59.999
+const (
+	minDate = -657435.0
+	maxDate = 2958466.0
+)
+
+// DateToTime converts an OLE Automation DATE (VT_DATE) into a time.Time. A DATE is the number of days since
+// 1899-12-30, with the time of day in the fraction. For dates before 1899-12-30 the fraction still counts forward from
+// midnight, so -1.25 is 1899-12-29 06:00. A DATE has no time zone, the result has the same wall clock in UTC and is
+// rounded to the millisecond like DateTime.FromOADate
+func DateToTime(date float64) (time.Time, error) {
+	if !(date > minDate && date < maxDate) {
+		return time.Time{}, fmt.Errorf("%v is not a valid OLE Automation date", date)
+	}
+	millis := int64(date*msPerDay + math.Copysign(0.5, date))
+	if millis < 0 {
+		// turn the time of day into an offset from midnight of the day before
+		millis -= (millis % msPerDay) * 2
+	}
+	days := millis / msPerDay
+	millis %= msPerDay
+	if millis < 0 {
+		days--
+		millis += msPerDay
+	}
+	return oleEpoch.AddDate(0, 0, int(days)).Add(time.Duration(millis) * time.Millisecond), nil
+}
+
+// TimeToDate converts t into an OLE Automation DATE (VT_DATE), using the wall clock of t in its own location and
+// dropping anything below a millisecond. See DateToTime for the format
+func TimeToDate(t time.Time) (float64, error) {
+	year, month, day := t.Date()
+	if year < 100 || year > 9999 {
+		return 0, fmt.Errorf("%v can't be represented as an OLE Automation date", t)
+	}
+	days := (time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() - oleEpoch.Unix()) / (24 * 60 * 60)
+	hour, min, sec := t.Clock()
+	millis := days*msPerDay + int64(((hour*60+min)*60+sec)*1000+t.Nanosecond()/1e6)
+	if millis < 0 {
+		// the time of day of a negative date counts forward, so it has to be mirrored
+		if frac := millis % msPerDay; frac != 0 {
+			millis -= (msPerDay + frac) * 2
+		}
+	}
+	return float64(millis) / msPerDay, nil
+}
+
+// Currency is an OLE Automation CY (VT_CY), a fixed point number stored as an int64 scaled by 10000 so it has exactly
+// four digits after the decimal point. Currency(12345) is 1.2345
+type Currency int64
+
+// CurrencyScale is the factor a Currency is scaled by
+const CurrencyScale = 10000
+
+// NewCurrency converts f into a Currency, rounding it to four digits after the decimal point
+func NewCurrency(f float64) (Currency, error) {
+	scaled := math.Round(f * CurrencyScale)
+	if math.IsNaN(scaled) || scaled < math.MinInt64 || scaled >= math.MaxInt64 {
+		return 0, fmt.Errorf("%v is out of range for a Currency", f)
+	}
+	return Currency(scaled), nil
+}
+
+// Float64 returns the value of the Currency as a float64, which may lose precision for large values
+func (c Currency) Float64() float64 {
+	return float64(c) / CurrencyScale
+}
+
+// String returns the exact value of the Currency with all four digits after the decimal point, e.g. "-1.2500"
+func (c Currency) String() string {
+	sign := ""
+	units := uint64(c)
+	if c < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%04d", sign, units/CurrencyScale, units%CurrencyScale)
+}
+
+// Decimal is an OLE Automation DECIMAL (VT_DECIMAL), a managed decimal. Its value is a 96-bit unsigned integer,
+// split into Hi32 and Lo64, divided by 10 to the power of Scale and negated if Negative is set
+type Decimal struct {
+	Scale    uint8 // 0 to 28
+	Negative bool
+	Hi32     uint32
+	Lo64     uint64
+}
+
+// the largest scale and coefficient a Decimal can hold
+const maxDecimalScale = 28
+
+var maxDecimalCoefficient = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 96), big.NewInt(1))
+
+// NewDecimal returns a Decimal with the value coefficient * 10^-scale. It fails if the coefficient doesn't fit in 96
+// bits or the scale is larger than 28
+func NewDecimal(coefficient *big.Int, scale uint8) (Decimal, error) {
+	if scale > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("a Decimal can't have a scale of %d", scale)
+	}
+	abs := new(big.Int).Abs(coefficient)
+	if abs.Cmp(maxDecimalCoefficient) > 0 {
+		return Decimal{}, fmt.Errorf("%s doesn't fit in the 96 bits of a Decimal", coefficient)
+	}
+	lo := new(big.Int).And(abs, new(big.Int).SetUint64(math.MaxUint64))
+	return Decimal{
+		Scale:    scale,
+		Negative: coefficient.Sign() < 0,
+		Hi32:     uint32(new(big.Int).Rsh(abs, 64).Uint64()),
+		Lo64:     lo.Uint64(),
+	}, nil
+}
+
+// ParseDecimal parses a decimal number such as "-123.4500" into a Decimal, keeping every digit after the decimal point
+func ParseDecimal(s string) (Decimal, error) {
+	digits, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits, fraction = s[:i], s[i+1:]
+	}
+	coefficient, ok := new(big.Int).SetString(digits+fraction, 10)
+	if !ok || strings.ContainsAny(fraction, "+-") || len(fraction) > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("%q is not a valid Decimal", s)
+	}
+	return NewDecimal(coefficient, uint8(len(fraction)))
+}
+
+// Coefficient returns the signed 96-bit integer the Decimal is scaled from
+func (d Decimal) Coefficient() *big.Int {
+	coefficient := new(big.Int).Lsh(new(big.Int).SetUint64(uint64(d.Hi32)), 64)
+	coefficient.Or(coefficient, new(big.Int).SetUint64(d.Lo64))
+	if d.Negative {
+		coefficient.Neg(coefficient)
+	}
+	return coefficient
+}
+
+// Rat returns the exact value of the Decimal
+func (d Decimal) Rat() *big.Rat {
+	denominator := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Scale)), nil)
+	return new(big.Rat).SetFrac(d.Coefficient(), denominator)
+}
+
+// Float64 returns the value of the Decimal as the nearest float64
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// String returns the exact value of the Decimal with Scale digits after the decimal point, like decimal.ToString
+func (d Decimal) String() string {
+	return d.Rat().FloatString(int(d.Scale))
+}
@@ -0,0 +1,232 @@
+package clr
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestDateToTime(t *testing.T) {
+	tests := []struct {
+		date float64
+		want time.Time
+	}{
+		{0, time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{1.5, time.Date(1899, 12, 31, 12, 0, 0, 0, time.UTC)},
+		{-1.25, time.Date(1899, 12, 29, 6, 0, 0, 0, time.UTC)},
+		{-0.5, time.Date(1899, 12, 30, 12, 0, 0, 0, time.UTC)},
+		{-657434, time.Date(100, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{2958465.5, time.Date(9999, 12, 31, 12, 0, 0, 0, time.UTC)},
+		{43831.0000000116, time.Date(2020, 1, 1, 0, 0, 0, 1e6, time.UTC)},
+	}
+	for _, test := range tests {
+		got, err := DateToTime(test.date)
+		if err != nil {
+			t.Errorf("DateToTime(%v) returned %v", test.date, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("DateToTime(%v) = %v, want %v", test.date, got, test.want)
+		}
+	}
+}
+
+func TestDateToTimeOutOfRange(t *testing.T) {
+	for _, date := range []float64{minDate, maxDate, minDate - 1, maxDate + 1, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if got, err := DateToTime(date); err == nil {
+			t.Errorf("DateToTime(%v) = %v, want an error", date, got)
+		}
+	}
+}
+
+func TestTimeToDate(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want float64
+	}{
+		{time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(1899, 12, 29, 6, 0, 0, 0, time.UTC), -1.25},
+		// the time of day of -0.5 counts forward from midnight, so it is the same instant as 0.5
+		{time.Date(1899, 12, 30, 12, 0, 0, 0, time.UTC), 0.5},
+		{time.Date(100, 1, 1, 0, 0, 0, 0, time.UTC), -657434},
+		{time.Date(9999, 12, 31, 12, 0, 0, 0, time.UTC), 2958465.5},
+		// only the wall clock counts, not the time zone
+		{time.Date(1900, 1, 1, 18, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60)), 2.75},
+		// anything below a millisecond is dropped
+		{time.Date(1899, 12, 30, 0, 0, 0, 999999, time.UTC), 0},
+	}
+	for _, test := range tests {
+		got, err := TimeToDate(test.t)
+		if err != nil {
+			t.Errorf("TimeToDate(%v) returned %v", test.t, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("TimeToDate(%v) = %v, want %v", test.t, got, test.want)
+		}
+	}
+}
+
+func TestTimeToDateOutOfRange(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Date(99, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got, err := TimeToDate(tm); err == nil {
+			t.Errorf("TimeToDate(%v) = %v, want an error", tm, got)
+		}
+	}
+}
+
+func TestDateRoundTrip(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Date(100, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1800, 6, 15, 23, 59, 59, 999e6, time.UTC),
+		time.Date(1899, 12, 29, 0, 0, 0, 1e6, time.UTC),
+		time.Date(2024, 2, 29, 13, 37, 0, 123e6, time.UTC),
+		time.Date(9999, 12, 31, 23, 59, 59, 999e6, time.UTC),
+	} {
+		date, err := TimeToDate(tm)
+		if err != nil {
+			t.Errorf("TimeToDate(%v) returned %v", tm, err)
+			continue
+		}
+		got, err := DateToTime(date)
+		if err != nil {
+			t.Errorf("DateToTime(%v) returned %v", date, err)
+			continue
+		}
+		if !got.Equal(tm) {
+			t.Errorf("%v became %v and then %v", tm, date, got)
+		}
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	tests := []struct {
+		c    Currency
+		want string
+	}{
+		{0, "0.0000"},
+		{12345, "1.2345"},
+		{-12500, "-1.2500"},
+		{-1, "-0.0001"},
+		{math.MaxInt64, "922337203685477.5807"},
+		{math.MinInt64, "-922337203685477.5808"},
+	}
+	for _, test := range tests {
+		if got := test.c.String(); got != test.want {
+			t.Errorf("Currency(%d).String() = %q, want %q", int64(test.c), got, test.want)
+		}
+	}
+	if got := Currency(-12500).Float64(); got != -1.25 {
+		t.Errorf("Currency(-12500).Float64() = %v, want -1.25", got)
+	}
+}
+
+func TestNewCurrency(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want Currency
+	}{
+		{1.2345, 12345},
+		{-1.25, -12500},
+		{0.00005, 1},
+		{-0.00004, 0},
+	}
+	for _, test := range tests {
+		got, err := NewCurrency(test.f)
+		if err != nil || got != test.want {
+			t.Errorf("NewCurrency(%v) = %d, %v, want %d", test.f, int64(got), err, int64(test.want))
+		}
+	}
+	for _, f := range []float64{1e15, -1e15, math.NaN(), math.Inf(1)} {
+		if got, err := NewCurrency(f); err == nil {
+			t.Errorf("NewCurrency(%v) = %d, want an error", f, int64(got))
+		}
+	}
+}
+
+func TestNewDecimal(t *testing.T) {
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 96), big.NewInt(1))
+	d, err := NewDecimal(max, 28)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Decimal{Scale: 28, Hi32: math.MaxUint32, Lo64: math.MaxUint64}
+	if d != want {
+		t.Errorf("NewDecimal(2^96-1, 28) = %#v, want %#v", d, want)
+	}
+	if got := d.String(); got != "7.9228162514264337593543950335" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := d.Coefficient(); got.Cmp(max) != 0 {
+		t.Errorf("Coefficient() = %v, want %v", got, max)
+	}
+
+	d, err = NewDecimal(new(big.Int).Neg(max), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.String(); got != "-79228162514264337593543950335" {
+		t.Errorf("String() = %q", got)
+	}
+
+	overflow := new(big.Int).Add(max, big.NewInt(1))
+	if _, err := NewDecimal(overflow, 0); err == nil {
+		t.Errorf("NewDecimal(2^96, 0) didn't fail")
+	}
+	if _, err := NewDecimal(new(big.Int).Neg(overflow), 0); err == nil {
+		t.Errorf("NewDecimal(-2^96, 0) didn't fail")
+	}
+	if _, err := NewDecimal(big.NewInt(1), 29); err == nil {
+		t.Errorf("NewDecimal(1, 29) didn't fail")
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Decimal
+	}{
+		{"0", Decimal{}},
+		{"-123.4500", Decimal{Scale: 4, Negative: true, Lo64: 1234500}},
+		{"+.5", Decimal{Scale: 1, Lo64: 5}},
+		{"18446744073709551616", Decimal{Hi32: 1}},
+		{"0.0000000000000000000000000001", Decimal{Scale: 28, Lo64: 1}},
+	}
+	for _, test := range tests {
+		got, err := ParseDecimal(test.s)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q) returned %v", test.s, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseDecimal(%q) = %#v, want %#v", test.s, got, test.want)
+		}
+		if got.String() != test.s && test.s[0] != '+' {
+			t.Errorf("ParseDecimal(%q).String() = %q", test.s, got.String())
+		}
+	}
+}
+
+func TestParseDecimalInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		".",
+		"1.-5",
+		"1.+5",
+		"1.2.3",
+		"abc",
+		"1e5",
+		// 29 digits after the decimal point
+		"0.00000000000000000000000000001",
+		// 2^96
+		"79228162514264337593543950336",
+	} {
+		if got, err := ParseDecimal(s); err == nil {
+			t.Errorf("ParseDecimal(%q) = %v, want an error", s, got)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// +build windows
+
+package clr
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// from mscorlib.tlh
+
+// ParameterInfo wraps the _ParameterInfo interface describing a parameter of a method. The interface only has the
+// IDispatch methods, so the parameter's properties are read through reflection
+type ParameterInfo struct {
+	vtbl *ParameterInfoVtbl
+}
+
+type ParameterInfoVtbl struct {
+	QueryInterface   uintptr
+	AddRef           uintptr
+	Release          uintptr
+	GetTypeInfoCount uintptr
+	GetTypeInfo      uintptr
+	GetIDsOfNames    uintptr
+	Invoke           uintptr
+}
+
+func NewParameterInfoFromPtr(ppv uintptr) *ParameterInfo {
+	return (*ParameterInfo)(unsafe.Pointer(ppv))
+}
+
+func (obj *ParameterInfo) QueryInterface(riid *windows.GUID, ppvObject *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.QueryInterface,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppvObject)))
+	return ret
+}
+
+func (obj *ParameterInfo) AddRef() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.AddRef,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *ParameterInfo) Release() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Release,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+// GetName returns the name of the parameter
+func (obj *ParameterInfo) GetName() (string, error) {
+	name, err := obj.getProperty("Name")
+	if err != nil {
+		return "", err
+	}
+	str, _ := name.(string)
+	return str, nil
+}
+
+// GetParameterType returns the type of the parameter
+func (obj *ParameterInfo) GetParameterType() (*Type, error) {
+	value, err := obj.getProperty("ParameterType")
+	if err != nil {
+		return nil, err
+	}
+	typeObject, ok := value.(*Object)
+	if !ok {
+		return nil, fmt.Errorf("ParameterType returned %T instead of a Type", value)
+	}
+	defer typeObject.Release()
+	var pType uintptr
+	hr := typeObject.QueryInterface(&IID_Type, &pType)
+	err = checkOK(hr, "object.QueryInterface")
+	if err != nil {
+		return nil, err
+	}
+	return NewTypeFromPtr(pType), nil
+}
+
+// GetPosition returns the zero based position of the parameter in the method's signature
+func (obj *ParameterInfo) GetPosition() (int32, error) {
+	position, err := obj.getProperty("Position")
+	if err != nil {
+		return -1, err
+	}
+	pos, _ := position.(int32)
+	return pos, nil
+}
+
+// IsOptional returns whether the parameter is optional
+func (obj *ParameterInfo) IsOptional() (bool, error) {
+	isOptional, err := obj.getProperty("IsOptional")
+	if err != nil {
+		return false, err
+	}
+	optional, _ := isOptional.(bool)
+	return optional, nil
+}
+
+// GetDefaultValue returns the default value of an optional parameter converted with ToGo, or nil if the parameter
+// has no default value
+func (obj *ParameterInfo) GetDefaultValue() (interface{}, error) {
+	o, err := obj.object()
+	if err != nil {
+		return nil, err
+	}
+	defer o.Release()
+	objType, err := o.GetRuntimeType()
+	if err != nil {
+		return nil, err
+	}
+	defer objType.Release()
+	ret, err := objType.invokeMember("DefaultValue", BindingFlagsGetProperty|BindingFlagsInstance|BindingFlagsPublic, o, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer VariantClear(&ret)
+	// parameters without a default value return DBNull, which comes back as VT_NULL, or Missing which comes back as
+	// VT_ERROR (DISP_E_PARAMNOTFOUND)
+	if ret.VT == VT_ERROR {
+		return nil, nil
+	}
+	return ret.ToGo()
+}
+
+// object returns the parameter's _Object interface, which gives us access to reflection
+func (obj *ParameterInfo) object() (*Object, error) {
+	var pObject uintptr
+	hr := obj.QueryInterface(&IID_Object, &pObject)
+	err := checkOK(hr, "parameterInfo.QueryInterface")
+	if err != nil {
+		return nil, err
+	}
+	return NewObjectFromPtr(pObject), nil
+}
+
+func (obj *ParameterInfo) getProperty(name string) (interface{}, error) {
+	o, err := obj.object()
+	if err != nil {
+		return nil, err
+	}
+	defer o.Release()
+	return o.getProperty(name)
+}
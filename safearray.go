@@ -3,8 +3,12 @@
 package clr
 
 import (
+	"fmt"
+	"reflect"
 	"syscall"
 	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
 // VARTYPE uint16
@@ -14,29 +18,14 @@ import (
 // LONG int32
 // USHORT uint16
 
-// from OAld.h
-
-type SafeArray struct {
-	cDims      uint16
-	fFeatures  uint16
-	cbElements uint32
-	cLocks     uint32
-	pvData     uintptr
-	rgsabound  [1]SafeArrayBound
-}
-
-type SafeArrayBound struct {
-	cElements uint32
-	lLbound   int32
-}
-
 // CreateSafeArray is a wrapper function that takes in a Go byte array and creates a SafeArray containing unsigned bytes
-// by making two syscalls and copying raw memory into the correct spot.
+// by making two syscalls and copying raw memory into the correct spot. Like every safe array created by this package,
+// the caller owns it and must free it with SafeArrayDestroy once it's no longer needed
 func CreateSafeArray(rawBytes []byte) (unsafe.Pointer, error) {
 
-	saPtr, err := CreateEmptySafeArray(0x11, len(rawBytes)) // VT_UI1
-	if err != nil {
-		return nil, err
+	saPtr, err := CreateEmptySafeArray(VT_UI1, len(rawBytes))
+	if err != nil || len(rawBytes) == 0 {
+		return saPtr, err
 	}
 	// now we need to use RtlCopyMemory to copy our bytes to the SafeArray
 	modNtDll := syscall.MustLoadDLL("ntdll.dll")
@@ -47,6 +36,7 @@ func CreateSafeArray(rawBytes []byte) (unsafe.Pointer, error) {
 		uintptr(unsafe.Pointer(&rawBytes[0])),
 		uintptr(len(rawBytes)))
 	if err != syscall.Errno(0) {
+		SafeArrayDestroy(saPtr)
 		return nil, err
 	}
 	return saPtr, nil
@@ -56,25 +46,87 @@ func CreateSafeArray(rawBytes []byte) (unsafe.Pointer, error) {
 // CreateEmptySafeArray is a wrapper function that takes an array type and a size and creates a safe array with corresponding
 // properties. It returns a pointer to that empty array.
 func CreateEmptySafeArray(arrayType int, size int) (unsafe.Pointer, error) {
+	return CreateMultiDimSafeArray(arrayType, size)
+}
+
+// CreateMultiDimSafeArray creates an empty, zero based safe array of arrayType elements with one dimension for each of
+// the lengths. The lengths are in the order the dimensions are declared in C#, so CreateMultiDimSafeArray(VT_I4, 2, 3)
+// creates the equivalent of a new int[2, 3], whose dimension 1 has an upper bound of 1 and dimension 2 one of 2
+func CreateMultiDimSafeArray(arrayType int, lengths ...int) (unsafe.Pointer, error) {
+	if len(lengths) == 0 {
+		return nil, fmt.Errorf("a safe array needs at least one dimension")
+	}
 	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
 	procSafeArrayCreate := modOleAuto.MustFindProc("SafeArrayCreate")
 
-	sab := SafeArrayBound{
-		cElements: uint32(size),
-		lLbound:   0,
+	// SafeArrayCreate takes the bounds left-most dimension first. The SAFEARRAY stores them in reverse, but dimension 1
+	// as seen by SafeArrayGetUBound is still lengths[0]
+	bounds := make([]SafeArrayBound, len(lengths))
+	for i, length := range lengths {
+		if length < 0 {
+			return nil, fmt.Errorf("a safe array can't have a dimension of length %d", length)
+		}
+		bounds[i] = SafeArrayBound{
+			cElements: uint32(length),
+			lLbound:   0,
+		}
 	}
-	vt := uint16(arrayType)
-	ret, _, err := procSafeArrayCreate.Call(
-		uintptr(vt),
-		uintptr(1),
-		uintptr(unsafe.Pointer(&sab)))
+	ret, _, _ := procSafeArrayCreate.Call(
+		uintptr(uint16(arrayType)),
+		uintptr(len(bounds)),
+		uintptr(unsafe.Pointer(&bounds[0])))
+	if ret == 0 {
+		return nil, fmt.Errorf("SafeArrayCreate failed to create a safe array of type 0x%04x", arrayType)
+	}
+	return unsafe.Pointer(ret), nil
+}
 
-	if err != syscall.Errno(0) {
+// CreateStringSafeArray is a wrapper function that takes in a slice of Go strings and creates a SafeArray of BSTRs
+// (a managed string[]) containing copies of them
+func CreateStringSafeArray(strs []string) (unsafe.Pointer, error) {
+	saPtr, err := CreateEmptySafeArray(VT_BSTR, len(strs))
+	if err != nil {
 		return nil, err
 	}
+	for i, str := range strs {
+		bstr, err := SysAllocString(str)
+		if err != nil {
+			SafeArrayDestroy(saPtr)
+			return nil, err
+		}
+		// SafeArrayPutElement stores its own copy of the BSTR
+		err = SafeArrayPutElement(saPtr, bstr, i)
+		SysFreeString(bstr)
+		if err != nil {
+			SafeArrayDestroy(saPtr)
+			return nil, err
+		}
+	}
+	return saPtr, nil
+}
 
-	return unsafe.Pointer(ret), nil
-
+// CreateVariantSafeArray is a wrapper function that converts each of the Go values with NewVariant and creates a
+// SafeArray of VARIANTs (a managed object[]) holding them, which is how arguments are passed to managed methods
+func CreateVariantSafeArray(values []interface{}) (unsafe.Pointer, error) {
+	saPtr, err := CreateEmptySafeArray(VT_VARIANT, len(values))
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		v, err := NewVariant(value)
+		if err != nil {
+			SafeArrayDestroy(saPtr)
+			return nil, err
+		}
+		// SafeArrayPutElement stores its own copy of the VARIANT
+		err = SafeArrayPutElement(saPtr, unsafe.Pointer(&v), i)
+		VariantClear(&v)
+		if err != nil {
+			SafeArrayDestroy(saPtr)
+			return nil, err
+		}
+	}
+	return saPtr, nil
 }
 
 // SysAllocString converts a Go string to a BTSR string, that is a unicode string prefixed with its length.
@@ -82,7 +134,7 @@ func CreateEmptySafeArray(arrayType int, size int) (unsafe.Pointer, error) {
 func SysAllocString(str string) (unsafe.Pointer, error) {
 	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
 	sysAllocString := modOleAuto.MustFindProc("SysAllocString")
-	input := utf16Le(str)
+	input := append(utf16Le(str), 0, 0) // SysAllocString expects a null terminated string
 	ret, _, err := sysAllocString.Call(
 		uintptr(unsafe.Pointer(&input[0])),
 	)
@@ -92,17 +144,355 @@ func SysAllocString(str string) (unsafe.Pointer, error) {
 	return unsafe.Pointer(ret), nil
 }
 
+// SysFreeString frees a BSTR previously allocated by SysAllocString or returned to us by a COM method
+func SysFreeString(bstr unsafe.Pointer) {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	sysFreeString := modOleAuto.MustFindProc("SysFreeString")
+	sysFreeString.Call(uintptr(bstr))
+}
+
 // SafeArrayPutElement pushes an element to the safe array at a given index
 func SafeArrayPutElement(array, btsr unsafe.Pointer, index int) (err error) {
+	return SafeArrayPutElementAt(array, btsr, index)
+}
+
+// SafeArrayPutElementAt copies an element into a multi-dimensional safe array at the given indices, which are in the
+// order the dimensions are declared in C#, e.g. SafeArrayPutElementAt(array, element, i, j) for array[i, j]. element
+// points to the value for scalar types, and is the BSTR, interface pointer or VARIANT pointer itself for the others
+func SafeArrayPutElementAt(array, element unsafe.Pointer, indices ...int) error {
+	if len(indices) == 0 {
+		return fmt.Errorf("SafeArrayPutElement needs at least one index")
+	}
 	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
 	safeArrayPutElement := modOleAuto.MustFindProc("SafeArrayPutElement")
-	_, _, err = safeArrayPutElement.Call(
+	// SafeArrayPutElement takes the indices as LONGs, in the same order as the bounds passed to SafeArrayCreate
+	rgIndices := make([]int32, len(indices))
+	for i, index := range indices {
+		rgIndices[i] = int32(index)
+	}
+	hr, _, _ := safeArrayPutElement.Call(
 		uintptr(array),
-		uintptr(unsafe.Pointer(&index)),
-		uintptr(btsr),
+		uintptr(unsafe.Pointer(&rgIndices[0])),
+		uintptr(element),
 	)
-	if err != syscall.Errno(0) {
+	return checkOK(hr, "SafeArrayPutElement")
+}
+
+// SafeArrayFrom creates a safe array holding copies of the elements of a Go slice or array, so that it can be passed
+// to managed code as a managed array. The element type picks the type of the array:
+//
+//	[]string                 string[]
+//	[]bool                   bool[]
+//	[]int8 ... []uint64      sbyte[] ... ulong[], []int and []uint become int[] and uint[]
+//	[]float32, []float64     float[], double[]
+//	[]time.Time              DateTime[]
+//	[]Currency, []Decimal    decimal[]
+//	[]*Object, []*Type...    object[] holding the managed objects
+//	[]interface{}            object[] with each element converted with NewVariant
+//	[]Variant                object[] holding copies of the Variants
+//
+// Nested slices or arrays become multi-dimensional arrays and have to be rectangular, so [][]int32{{1, 2, 3}, {4, 5,
+// 6}} becomes an int[2, 3]. The caller owns the returned array and must destroy it with SafeArrayDestroy
+func SafeArrayFrom(value interface{}) (unsafe.Pointer, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("can't create a safe array from %T", value)
+	}
+	if b, ok := value.([]byte); ok && len(b) > 0 {
+		return CreateSafeArray(b)
+	}
+
+	lengths, elemType := arrayShape(v)
+	vt, err := safeArrayVartype(elemType)
+	if err != nil {
+		return nil, err
+	}
+	array, err := CreateMultiDimSafeArray(int(vt), lengths...)
+	if err != nil {
+		return nil, err
+	}
+	err = arrayElements(v, lengths, func(indices []int, value interface{}) error {
+		return safeArrayPut(array, vt, value, indices)
+	})
+	if err != nil {
+		SafeArrayDestroy(array)
+		return nil, err
+	}
+	return array, nil
+}
+
+// safeArrayVartype returns the type of safe array elements that values of Go type t are stored as
+func safeArrayVartype(t reflect.Type) (uint16, error) {
+	switch t {
+	case reflect.TypeOf(&IUnknown{}), reflect.TypeOf(&Object{}), reflect.TypeOf(&Type{}), reflect.TypeOf(&Assembly{}), reflect.TypeOf(&AppDomain{}):
+		return VT_UNKNOWN, nil
+	}
+	return elementVartype(t)
+}
+
+// safeArrayPut stores value at indices in the safe array of vt elements
+func safeArrayPut(array unsafe.Pointer, vt uint16, value interface{}, indices []int) error {
+	if variant, ok := value.(Variant); ok && vt == VT_VARIANT {
+		return SafeArrayPutElementAt(array, unsafe.Pointer(&variant), indices...)
+	}
+	element, err := NewVariant(value)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer VariantClear(&element)
+	switch {
+	case vt == VT_VARIANT:
+		return SafeArrayPutElementAt(array, unsafe.Pointer(&element), indices...)
+	case element.VT == VT_EMPTY:
+		// a nil object, the element is already null
+		return nil
+	case vt == VT_BSTR || vt == VT_UNKNOWN:
+		// SafeArrayPutElement copies the BSTR or AddRefs the interface itself
+		return SafeArrayPutElementAt(array, *(*unsafe.Pointer)(element.payload()), indices...)
+	case vt == VT_DECIMAL:
+		// the DECIMAL takes up the whole Variant, but its reserved bytes must be 0 outside of one
+		decimal := element
+		decimal.VT = 0
+		return SafeArrayPutElementAt(array, unsafe.Pointer(&decimal), indices...)
+	default:
+		return SafeArrayPutElementAt(array, element.payload(), indices...)
+	}
+}
+
+// SafeArrayDestroy destroys a safe array and everything it holds: BSTRs are freed, VARIANTs are cleared and interface
+// pointers are released
+func SafeArrayDestroy(array unsafe.Pointer) error {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayDestroy := modOleAuto.MustFindProc("SafeArrayDestroy")
+	hr, _, _ := safeArrayDestroy.Call(uintptr(array))
+	return checkOK(hr, "SafeArrayDestroy")
+}
+
+// SafeArrayGetDim returns the number of dimensions of the safe array
+func SafeArrayGetDim(array unsafe.Pointer) uint32 {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayGetDim := modOleAuto.MustFindProc("SafeArrayGetDim")
+	ret, _, _ := safeArrayGetDim.Call(uintptr(array))
+	return uint32(ret)
+}
+
+// SafeArrayGetLBound returns the lower bound of one of the dimensions of the safe array. Dimensions are numbered from 1
+func SafeArrayGetLBound(array unsafe.Pointer, dim uint32) (int32, error) {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayGetLBound := modOleAuto.MustFindProc("SafeArrayGetLBound")
+	var lbound int32
+	hr, _, _ := safeArrayGetLBound.Call(
+		uintptr(array),
+		uintptr(dim),
+		uintptr(unsafe.Pointer(&lbound)))
+	return lbound, checkOK(hr, "SafeArrayGetLBound")
+}
+
+// SafeArrayGetUBound returns the upper bound of one of the dimensions of the safe array. Dimensions are numbered from 1
+func SafeArrayGetUBound(array unsafe.Pointer, dim uint32) (int32, error) {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayGetUBound := modOleAuto.MustFindProc("SafeArrayGetUBound")
+	var ubound int32
+	hr, _, _ := safeArrayGetUBound.Call(
+		uintptr(array),
+		uintptr(dim),
+		uintptr(unsafe.Pointer(&ubound)))
+	return ubound, checkOK(hr, "SafeArrayGetUBound")
+}
+
+// SafeArrayGetVartype returns the VARTYPE of the elements of the safe array
+func SafeArrayGetVartype(array unsafe.Pointer) (uint16, error) {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayGetVartype := modOleAuto.MustFindProc("SafeArrayGetVartype")
+	var vt uint16
+	hr, _, _ := safeArrayGetVartype.Call(
+		uintptr(array),
+		uintptr(unsafe.Pointer(&vt)))
+	return vt, checkOK(hr, "SafeArrayGetVartype")
+}
+
+// SafeArrayGetElemsize returns the size in bytes of each element of the safe array
+func SafeArrayGetElemsize(array unsafe.Pointer) uint32 {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayGetElemsize := modOleAuto.MustFindProc("SafeArrayGetElemsize")
+	ret, _, _ := safeArrayGetElemsize.Call(uintptr(array))
+	return uint32(ret)
+}
+
+// SafeArrayAccessData locks the safe array and returns a pointer to its elements, which stays valid until
+// SafeArrayUnaccessData is called
+func SafeArrayAccessData(array unsafe.Pointer) (unsafe.Pointer, error) {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayAccessData := modOleAuto.MustFindProc("SafeArrayAccessData")
+	var data unsafe.Pointer
+	hr, _, _ := safeArrayAccessData.Call(
+		uintptr(array),
+		uintptr(unsafe.Pointer(&data)))
+	err := checkOK(hr, "SafeArrayAccessData")
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// SafeArrayUnaccessData unlocks a safe array locked by SafeArrayAccessData
+func SafeArrayUnaccessData(array unsafe.Pointer) error {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	safeArrayUnaccessData := modOleAuto.MustFindProc("SafeArrayUnaccessData")
+	hr, _, _ := safeArrayUnaccessData.Call(uintptr(array))
+	return checkOK(hr, "SafeArrayUnaccessData")
+}
+
+// SafeArrayToBytes copies the elements of a one dimensional safe array of VT_UI1 (a managed byte[]) into a byte slice
+func SafeArrayToBytes(array unsafe.Pointer) ([]byte, error) {
+	data, count, err := safeArrayAccessVector(array, VT_UI1, VT_I1)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	defer SafeArrayUnaccessData(array)
+	out := make([]byte, count)
+	copy(out, unsafe.Slice((*byte)(data), count))
+	return out, nil
+}
+
+// SafeArrayToStrings copies the elements of a one dimensional safe array of VT_BSTR (a managed string[]) into a string
+// slice. Null strings become empty strings
+func SafeArrayToStrings(array unsafe.Pointer) ([]string, error) {
+	data, count, err := safeArrayAccessVector(array, VT_BSTR)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	defer SafeArrayUnaccessData(array)
+	bstrs := unsafe.Slice((*unsafe.Pointer)(data), count)
+	out := make([]string, count)
+	for i, bstr := range bstrs {
+		if bstr != nil {
+			out[i] = readUnicodeStr(bstr)
+		}
+	}
+	return out, nil
+}
+
+// SafeArrayToInt32s copies the elements of a one dimensional safe array of VT_I4 (a managed int[]) into an int32 slice
+func SafeArrayToInt32s(array unsafe.Pointer) ([]int32, error) {
+	data, count, err := safeArrayAccessVector(array, VT_I4, VT_INT)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	defer SafeArrayUnaccessData(array)
+	out := make([]int32, count)
+	copy(out, unsafe.Slice((*int32)(data), count))
+	return out, nil
+}
+
+// SafeArrayToVariants copies the elements of a one dimensional safe array of VT_VARIANT (a managed object[]) into a
+// Variant slice. Each Variant is a copy made with VariantCopy that the caller owns and must clear with VariantClear
+func SafeArrayToVariants(array unsafe.Pointer) ([]Variant, error) {
+	data, count, err := safeArrayAccessVector(array, VT_VARIANT)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	defer SafeArrayUnaccessData(array)
+	variants := unsafe.Slice((*Variant)(data), count)
+	out := make([]Variant, count)
+	for i := range variants {
+		if err := VariantCopy(&out[i], &variants[i]); err != nil {
+			for j := 0; j < i; j++ {
+				VariantClear(&out[j])
+			}
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// SafeArrayToUnknowns returns the interface pointers held by a one dimensional safe array of VT_UNKNOWN or VT_DISPATCH
+// elements. Each of them is AddRef'd, so the caller must Release them. Null elements are returned as nil
+func SafeArrayToUnknowns(array unsafe.Pointer) ([]*IUnknown, error) {
+	data, count, err := safeArrayAccessVector(array, VT_UNKNOWN, VT_DISPATCH)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	defer SafeArrayUnaccessData(array)
+	ptrs := unsafe.Slice((*uintptr)(data), count)
+	out := make([]*IUnknown, count)
+	for i, ptr := range ptrs {
+		if ptr != 0 {
+			out[i] = NewIUnknownFromPtr(ptr)
+			out[i].AddRef()
+		}
+	}
+	return out, nil
+}
+
+// safeArrayAccessVector checks that the safe array has one dimension and elements of one of the types in vts, then
+// locks it with SafeArrayAccessData and returns its data along with the number of elements, which doesn't depend on the
+// lower bound. The caller must call SafeArrayUnaccessData unless an error or no elements are returned
+func safeArrayAccessVector(array unsafe.Pointer, vts ...uint16) (unsafe.Pointer, int, error) {
+	if array == nil {
+		return nil, 0, nil
+	}
+	if dims := SafeArrayGetDim(array); dims != 1 {
+		return nil, 0, fmt.Errorf("expected a safe array with 1 dimension, got %d", dims)
+	}
+	vt, err := SafeArrayGetVartype(array)
+	if err != nil {
+		return nil, 0, err
+	}
+	ok := false
+	for _, expected := range vts {
+		ok = ok || vt == expected
+	}
+	if !ok {
+		return nil, 0, fmt.Errorf("expected a safe array of type 0x%04x, got 0x%04x", vts[0], vt)
+	}
+	count, err := safeArrayCount(array, 1)
+	if err != nil || count == 0 {
+		return nil, 0, err
+	}
+	data, err := SafeArrayAccessData(array)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, count, nil
+}
+
+// safeArrayCount returns the number of elements in one of the dimensions of the safe array
+func safeArrayCount(array unsafe.Pointer, dim uint32) (int, error) {
+	lbound, err := SafeArrayGetLBound(array, dim)
+	if err != nil {
+		return 0, err
+	}
+	ubound, err := SafeArrayGetUBound(array, dim)
+	if err != nil {
+		return 0, err
+	}
+	return int(int64(ubound) - int64(lbound) + 1), nil
+}
+
+// safeArrayInterfaces reads the interface pointers out of a one dimensional safe array of VT_UNKNOWN or VT_DISPATCH
+// elements (such as the SAFEARRAY(_Assembly*) returned by GetAssemblies) and queries each of them for riid. The array is
+// destroyed afterwards, the caller owns a reference to every pointer returned. Null elements are skipped
+func safeArrayInterfaces(array unsafe.Pointer, riid *windows.GUID) ([]uintptr, error) {
+	defer SafeArrayDestroy(array)
+	unknowns, err := SafeArrayToUnknowns(array)
+	if err != nil {
+		return nil, err
+	}
+	ptrs := make([]uintptr, 0, len(unknowns))
+	for _, unknown := range unknowns {
+		if unknown == nil {
+			continue
+		}
+		var ppv uintptr
+		hr := unknown.QueryInterface(riid, &ppv)
+		unknown.Release()
+		if hr != S_OK {
+			// keep going so we hold on to the ones that did work and the caller can release them
+			err = checkOK(hr, "IUnknown.QueryInterface")
+			continue
+		}
+		ptrs = append(ptrs, ppv)
+	}
+	return ptrs, err
 }
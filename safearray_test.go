@@ -0,0 +1,79 @@
+// +build windows
+
+package clr
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// checkBounds checks that the dimensions of the safe array have the lengths in the order they were passed in
+func checkBounds(t *testing.T, array unsafe.Pointer, lengths ...int) {
+	t.Helper()
+	if dims := SafeArrayGetDim(array); dims != uint32(len(lengths)) {
+		t.Fatalf("SafeArrayGetDim = %d, want %d", dims, len(lengths))
+	}
+	for i, length := range lengths {
+		ubound, err := SafeArrayGetUBound(array, uint32(i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ubound != int32(length-1) {
+			t.Errorf("SafeArrayGetUBound(%d) = %d, want %d", i+1, ubound, length-1)
+		}
+	}
+}
+
+func TestCreateMultiDimSafeArray(t *testing.T) {
+	array, err := CreateMultiDimSafeArray(VT_I4, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SafeArrayDestroy(array)
+	checkBounds(t, array, 2, 3)
+
+	value := int32(42)
+	if err := SafeArrayPutElementAt(array, unsafe.Pointer(&value), 1, 2); err != nil {
+		t.Errorf("SafeArrayPutElementAt(1, 2) returned %v", err)
+	}
+	if err := SafeArrayPutElementAt(array, unsafe.Pointer(&value), 2, 1); err == nil {
+		t.Errorf("SafeArrayPutElementAt(2, 1) didn't fail on an int[2, 3]")
+	}
+}
+
+func TestSafeArrayFromNested(t *testing.T) {
+	array, err := SafeArrayFrom([][]int32{{1, 2, 3}, {4, 5, 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SafeArrayDestroy(array)
+	checkBounds(t, array, 2, 3)
+
+	if _, err := SafeArrayFrom([][]int32{{1, 2, 3}, {4, 5}}); err == nil {
+		t.Errorf("SafeArrayFrom didn't fail on a jagged slice")
+	}
+}
+
+func TestSafeArrayFromVariants(t *testing.T) {
+	array, err := SafeArrayFrom([]Variant{NewVariantInt32(42), NewVariantBool(true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SafeArrayDestroy(array)
+	variants, err := SafeArrayToVariants(array)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range variants {
+		defer VariantClear(&variants[i])
+	}
+	if len(variants) != 2 {
+		t.Fatalf("got %d elements, want 2", len(variants))
+	}
+	if got, err := variants[0].ToGo(); err != nil || got != int32(42) {
+		t.Errorf("element 0 = %#v, %v, want int32(42)", got, err)
+	}
+	if got, err := variants[1].ToGo(); err != nil || got != true {
+		t.Errorf("element 1 = %#v, %v, want true", got, err)
+	}
+}
@@ -0,0 +1,427 @@
+// +build windows
+
+package clr
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// from mscorlib.tlh
+
+// Type wraps the _Type interface of a managed System.Type
+type Type struct {
+	vtbl *TypeVtbl
+}
+
+type TypeVtbl struct {
+	QueryInterface            uintptr
+	AddRef                    uintptr
+	Release                   uintptr
+	GetTypeInfoCount          uintptr
+	GetTypeInfo               uintptr
+	GetIDsOfNames             uintptr
+	Invoke                    uintptr
+	get_ToString              uintptr
+	Equals                    uintptr
+	GetHashCode               uintptr
+	GetType                   uintptr
+	get_MemberType            uintptr
+	get_name                  uintptr
+	get_DeclaringType         uintptr
+	get_ReflectedType         uintptr
+	GetCustomAttributes       uintptr
+	GetCustomAttributes_2     uintptr
+	IsDefined                 uintptr
+	get_Guid                  uintptr
+	get_Module                uintptr
+	get_Assembly              uintptr
+	get_TypeHandle            uintptr
+	get_FullName              uintptr
+	get_Namespace             uintptr
+	get_AssemblyQualifiedName uintptr
+	GetArrayRank              uintptr
+	get_BaseType              uintptr
+	GetConstructors           uintptr
+	GetInterface              uintptr
+	GetInterfaces             uintptr
+	FindInterfaces            uintptr
+	GetEvent                  uintptr
+	GetEvents                 uintptr
+	GetEvents_2               uintptr
+	GetNestedTypes            uintptr
+	GetNestedType             uintptr
+	GetMember                 uintptr
+	GetDefaultMembers         uintptr
+	FindMembers               uintptr
+	GetElementType            uintptr
+	IsSubclassOf              uintptr
+	IsInstanceOfType          uintptr
+	IsAssignableFrom          uintptr
+	GetInterfaceMap           uintptr
+	GetMethod                 uintptr
+	GetMethod_2               uintptr
+	GetMethods                uintptr
+	GetField                  uintptr
+	GetFields                 uintptr
+	GetProperty               uintptr
+	GetProperty_2             uintptr
+	GetProperties             uintptr
+	GetMember_2               uintptr
+	GetMembers                uintptr
+	InvokeMember              uintptr
+	get_UnderlyingSystemType  uintptr
+	InvokeMember_2            uintptr
+	InvokeMember_3            uintptr
+	GetConstructor            uintptr
+	GetConstructor_2          uintptr
+	GetConstructor_3          uintptr
+	GetConstructors_2         uintptr
+	get_TypeInitializer       uintptr
+	GetMethod_3               uintptr
+	GetMethod_4               uintptr
+	GetMethod_5               uintptr
+	GetMethod_6               uintptr
+	GetMethods_2              uintptr
+	GetField_2                uintptr
+	GetFields_2               uintptr
+	GetInterface_2            uintptr
+	GetEvent_2                uintptr
+	GetProperty_3             uintptr
+	GetProperty_4             uintptr
+	GetProperty_5             uintptr
+	GetProperty_6             uintptr
+	GetProperty_7             uintptr
+	GetProperties_2           uintptr
+	GetNestedTypes_2          uintptr
+	GetNestedType_2           uintptr
+	GetMember_3               uintptr
+	GetMembers_2              uintptr
+	get_Attributes            uintptr
+	get_IsNotPublic           uintptr
+	get_IsPublic              uintptr
+	get_IsNestedPublic        uintptr
+	get_IsNestedPrivate       uintptr
+	get_IsNestedFamily        uintptr
+	get_IsNestedAssembly      uintptr
+	get_IsNestedFamANDAssem   uintptr
+	get_IsNestedFamORAssem    uintptr
+	get_IsAutoLayout          uintptr
+	get_IsLayoutSequential    uintptr
+	get_IsExplicitLayout      uintptr
+	get_IsClass               uintptr
+	get_IsInterface           uintptr
+	get_IsValueType           uintptr
+	get_IsAbstract            uintptr
+	get_IsSealed              uintptr
+	get_IsEnum                uintptr
+	get_IsSpecialName         uintptr
+	get_IsImport              uintptr
+	get_IsSerializable        uintptr
+	get_IsAnsiClass           uintptr
+	get_IsUnicodeClass        uintptr
+	get_IsAutoClass           uintptr
+	get_IsArray               uintptr
+	get_IsByRef               uintptr
+	get_IsPointer             uintptr
+	get_IsPrimitive           uintptr
+	get_IsCOMObject           uintptr
+	get_HasElementType        uintptr
+	get_IsContextful          uintptr
+	get_IsMarshalByRef        uintptr
+	Equals_2                  uintptr
+}
+
+func NewTypeFromPtr(ppv uintptr) *Type {
+	return (*Type)(unsafe.Pointer(ppv))
+}
+
+func (obj *Type) QueryInterface(riid *windows.GUID, ppvObject *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.QueryInterface,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(riid)),
+		uintptr(unsafe.Pointer(ppvObject)))
+	return ret
+}
+
+func (obj *Type) AddRef() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.AddRef,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *Type) Release() uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.Release,
+		1,
+		uintptr(unsafe.Pointer(obj)),
+		0,
+		0)
+	return ret
+}
+
+func (obj *Type) InvokeMember_3(name uintptr, invokeAttr BindingFlags, binder uintptr, target Variant, args uintptr, pRetVal *Variant) uintptr {
+	if variantByValue {
+		v := target.words()
+		ret, _, _ := syscall.Syscall12(
+			obj.vtbl.InvokeMember_3,
+			10,
+			uintptr(unsafe.Pointer(obj)),
+			name,
+			uintptr(invokeAttr),
+			binder,
+			v[0],
+			v[1],
+			v[2],
+			v[3],
+			args,
+			uintptr(unsafe.Pointer(pRetVal)),
+			0,
+			0)
+		return ret
+	}
+	ret, _, _ := syscall.Syscall9(
+		obj.vtbl.InvokeMember_3,
+		7,
+		uintptr(unsafe.Pointer(obj)),
+		name,
+		uintptr(invokeAttr),
+		binder,
+		uintptr(unsafe.Pointer(&target)),
+		args,
+		uintptr(unsafe.Pointer(pRetVal)),
+		0,
+		0)
+	return ret
+}
+
+// invokeMember calls InvokeMember_3 with the default binder. target is converted with NewVariant and should be nil
+// for static members, args are passed as an object[]. The caller owns the returned Variant and must clear it
+func (obj *Type) invokeMember(name string, invokeAttr BindingFlags, target interface{}, args []interface{}) (Variant, error) {
+	var ret Variant
+	bstrName, err := SysAllocString(name)
+	if err != nil {
+		return ret, err
+	}
+	defer SysFreeString(bstrName)
+	targetVariant, err := NewVariant(target)
+	if err != nil {
+		return ret, err
+	}
+	defer VariantClear(&targetVariant)
+	pArgs, err := CreateVariantSafeArray(args)
+	if err != nil {
+		return ret, err
+	}
+	defer SafeArrayDestroy(pArgs)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	hr := obj.InvokeMember_3(uintptr(bstrName), invokeAttr, 0, targetVariant, uintptr(pArgs), &ret)
+	return ret, checkManagedOK(hr, "type.InvokeMember_3("+name+")")
+}
+
+func (obj *Type) GetMethods(bindingAttr BindingFlags, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.GetMethods,
+		3,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(bindingAttr),
+		uintptr(unsafe.Pointer(pRetVal)))
+	return ret
+}
+
+func (obj *Type) GetMethod_2(name uintptr, bindingAttr BindingFlags, pRetVal *uintptr) uintptr {
+	ret, _, _ := syscall.Syscall6(
+		obj.vtbl.GetMethod_2,
+		4,
+		uintptr(unsafe.Pointer(obj)),
+		name,
+		uintptr(bindingAttr),
+		uintptr(unsafe.Pointer(pRetVal)),
+		0,
+		0)
+	return ret
+}
+
+// Methods returns the methods of the type matching bindingAttr, e.g. BindingFlagsPublic|BindingFlagsStatic. The caller
+// owns a reference to each MethodInfo returned
+func (obj *Type) Methods(bindingAttr BindingFlags) ([]*MethodInfo, error) {
+	var pMethods uintptr
+	hr := obj.GetMethods(bindingAttr, &pMethods)
+	err := checkOK(hr, "type.GetMethods")
+	if err != nil {
+		return nil, err
+	}
+	ptrs, err := safeArrayInterfaces(unsafe.Pointer(pMethods), &IID_MethodInfo)
+	methods := make([]*MethodInfo, len(ptrs))
+	for i, ptr := range ptrs {
+		methods[i] = NewMethodInfoFromPtr(ptr)
+	}
+	return methods, err
+}
+
+// GetMethodByName returns the method called name that matches bindingAttr. If the method is overloaded the CLR
+// returns an AmbiguousMatchException, use Methods to pick the right one instead
+func (obj *Type) GetMethodByName(name string, bindingAttr BindingFlags) (*MethodInfo, error) {
+	bstrName, err := SysAllocString(name)
+	if err != nil {
+		return nil, err
+	}
+	defer SysFreeString(bstrName)
+	var pMethodInfo uintptr
+	hr := obj.GetMethod_2(uintptr(bstrName), bindingAttr, &pMethodInfo)
+	err = checkOK(hr, "type.GetMethod_2")
+	if err != nil {
+		return nil, err
+	}
+	if pMethodInfo == 0 {
+		return nil, fmt.Errorf("method %s was not found", name)
+	}
+	return NewMethodInfoFromPtr(pMethodInfo), nil
+}
+
+// ToString returns the result of calling ToString on the managed type, which is its name in C# style
+func (obj *Type) ToString() (string, error) {
+	return obj.getString(obj.vtbl.get_ToString, "type.get_ToString")
+}
+
+// GetFullName returns the fully qualified name of the type, including its namespace but not its assembly
+func (obj *Type) GetFullName() (string, error) {
+	return obj.getString(obj.vtbl.get_FullName, "type.get_FullName")
+}
+
+// GetName returns the name of the type without its namespace
+func (obj *Type) GetName() (string, error) {
+	return obj.getString(obj.vtbl.get_name, "type.get_name")
+}
+
+// GetNamespace returns the namespace of the type
+func (obj *Type) GetNamespace() (string, error) {
+	return obj.getString(obj.vtbl.get_Namespace, "type.get_Namespace")
+}
+
+// GetAssemblyQualifiedName returns the full name of the type followed by the display name of its assembly
+func (obj *Type) GetAssemblyQualifiedName() (string, error) {
+	return obj.getString(obj.vtbl.get_AssemblyQualifiedName, "type.get_AssemblyQualifiedName")
+}
+
+// GetBaseType returns the type this type directly inherits from, or nil for System.Object and interfaces
+func (obj *Type) GetBaseType() (*Type, error) {
+	var pType uintptr
+	ret, _, _ := syscall.Syscall(
+		obj.vtbl.get_BaseType,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&pType)),
+		0)
+	if err := checkOK(ret, "type.get_BaseType"); err != nil {
+		return nil, err
+	}
+	if pType == 0 {
+		return nil, nil
+	}
+	return NewTypeFromPtr(pType), nil
+}
+
+// IsClass returns whether the type is a class or a delegate, that is not a value type or an interface
+func (obj *Type) IsClass() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsClass, "type.get_IsClass")
+}
+
+// IsInterface returns whether the type is an interface
+func (obj *Type) IsInterface() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsInterface, "type.get_IsInterface")
+}
+
+// IsEnum returns whether the type is an enum
+func (obj *Type) IsEnum() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsEnum, "type.get_IsEnum")
+}
+
+// IsValueType returns whether the type is a value type
+func (obj *Type) IsValueType() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsValueType, "type.get_IsValueType")
+}
+
+// IsAbstract returns whether the type is abstract and can't be instantiated
+func (obj *Type) IsAbstract() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsAbstract, "type.get_IsAbstract")
+}
+
+// IsPublic returns whether the type is public and not nested
+func (obj *Type) IsPublic() (bool, error) {
+	return obj.getBool(obj.vtbl.get_IsPublic, "type.get_IsPublic")
+}
+
+// getString calls one of the type's methods that returns a BSTR
+func (obj *Type) getString(method uintptr, caller string) (string, error) {
+	var bstr uintptr
+	ret, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if err := checkOK(ret, caller); err != nil {
+		return "", err
+	}
+	return readBSTR(bstr), nil
+}
+
+// getBool calls one of the type's methods that returns a VARIANT_BOOL
+func (obj *Type) getBool(method uintptr, caller string) (bool, error) {
+	var value int16 // VARIANT_BOOL
+	ret, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(obj)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if err := checkOK(ret, caller); err != nil {
+		return false, err
+	}
+	return value != 0, nil
+}
+
+// typesFromSafeArray turns the SAFEARRAY(_Type*) returned by methods such as GetTypes into Types and destroys it
+func typesFromSafeArray(pTypes uintptr) ([]*Type, error) {
+	ptrs, err := safeArrayInterfaces(unsafe.Pointer(pTypes), &IID_Type)
+	types := make([]*Type, len(ptrs))
+	for i, ptr := range ptrs {
+		types[i] = NewTypeFromPtr(ptr)
+	}
+	return types, err
+}
+
+// InvokeMember invokes the member called name of the type through reflection, which is enough to drive most of the
+// .NET base library without any more vtables. What happens depends on invokeAttr:
+//
+//	BindingFlagsInvokeMethod    calls the method name with args
+//	BindingFlagsGetProperty     returns the value of the property name, args are indexes for indexed properties
+//	BindingFlagsSetProperty     sets the property name to the last element of args
+//	BindingFlagsGetField        returns the value of the field name
+//	BindingFlagsSetField        sets the field name to args[0]
+//	BindingFlagsCreateInstance  calls the constructor matching args (name is ignored) and returns the new object
+//
+// target is the object to invoke the member on, or nil for static members and constructors. If invokeAttr doesn't
+// restrict the lookup with BindingFlagsPublic, BindingFlagsInstance etc. all public members are considered. The args
+// are converted with NewVariant and the result with ToGo, so members without a return value return nil
+func (obj *Type) InvokeMember(name string, invokeAttr BindingFlags, target *Object, args ...interface{}) (interface{}, error) {
+	ret, err := obj.invokeMember(name, invokeAttr, target, args)
+	if err != nil {
+		return nil, err
+	}
+	defer VariantClear(&ret)
+	return ret.ToGo()
+}
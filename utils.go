@@ -6,7 +6,6 @@ import (
 	"bytes"
 	"fmt"
 	"log"
-	"strings"
 	"unicode/utf16"
 	"unsafe"
 
@@ -38,10 +37,6 @@ func utf16Le(s string) []byte {
 	return buf.Bytes()
 }
 
-func expectsParams(input string) bool {
-	return !strings.Contains(input, "Void Main()")
-}
-
 func readUnicodeStr(ptr unsafe.Pointer) string {
 	var byteVal uint16
 	out := make([]uint16, 0)
@@ -55,3 +50,12 @@ func readUnicodeStr(ptr unsafe.Pointer) string {
 	}
 	return string(utf16.Decode(out))
 }
+
+// readBSTR reads a BSTR handed to us by a COM method into a Go string and frees it
+func readBSTR(bstr uintptr) string {
+	if bstr == 0 {
+		return ""
+	}
+	defer SysFreeString(unsafe.Pointer(bstr))
+	return readUnicodeStr(unsafe.Pointer(bstr))
+}
@@ -2,19 +2,286 @@
 
 package clr
 
-import "unsafe"
+import (
+	"fmt"
+	"reflect"
+	"syscall"
+	"time"
+	"unsafe"
+)
 
-// from https://github.com/go-ole/go-ole/blob/master/variant_amd64.go
+func NewVariantFromPtr(ppv uintptr) *Variant {
+	return (*Variant)(unsafe.Pointer(ppv))
+}
 
-type Variant struct {
-	VT         uint16 // VARTYPE
-	wReserved1 uint16
-	wReserved2 uint16
-	wReserved3 uint16
-	Val        uintptr
-	_          [8]byte
+// NewVariantBSTR returns a VT_BSTR Variant holding a copy of str, a managed string
+func NewVariantBSTR(str string) (Variant, error) {
+	bstr, err := SysAllocString(str)
+	if err != nil {
+		return Variant{}, err
+	}
+	return Variant{VT: VT_BSTR, Val: uintptr(bstr)}, nil
 }
 
-func NewVariantFromPtr(ppv uintptr) *Variant {
-	return (*Variant)(unsafe.Pointer(ppv))
+// NewVariant converts a Go value into a Variant that can be handed to managed code. Supported types are nil, string,
+// bool, all of Go's integer and floating point types, time.Time, Currency, Decimal, []byte (which becomes a managed
+// byte[]), handles to managed objects such as *Object or *Type and slices of any of these (see SafeArrayFrom).
+// Strings and arrays are copied into memory owned by the Variant and objects are AddRef'd, so it should be cleared
+// with VariantClear once it is no longer needed
+func NewVariant(value interface{}) (Variant, error) {
+	switch v := value.(type) {
+	case nil:
+		return Variant{VT: VT_EMPTY}, nil
+	case string:
+		return NewVariantBSTR(v)
+	case int:
+		if int(int32(v)) == v {
+			return NewVariantInt32(int32(v)), nil
+		}
+		return NewVariantInt64(int64(v)), nil
+	case int8:
+		return NewVariantInt8(v), nil
+	case int16:
+		return NewVariantInt16(v), nil
+	case int32:
+		return NewVariantInt32(v), nil
+	case int64:
+		return NewVariantInt64(v), nil
+	case uint:
+		if uint(uint32(v)) == v {
+			return NewVariantUint32(uint32(v)), nil
+		}
+		return NewVariantUint64(uint64(v)), nil
+	case uint8:
+		return NewVariantUint8(v), nil
+	case uint16:
+		return NewVariantUint16(v), nil
+	case uint32:
+		return NewVariantUint32(v), nil
+	case uint64:
+		return NewVariantUint64(v), nil
+	case float32:
+		return NewVariantFloat32(v), nil
+	case float64:
+		return NewVariantFloat64(v), nil
+	case bool:
+		return NewVariantBool(v), nil
+	case time.Time:
+		return NewVariantDate(v)
+	case Currency:
+		return NewVariantCurrency(v), nil
+	case Decimal:
+		return NewVariantDecimal(v), nil
+	case []byte:
+		saPtr, err := CreateSafeArray(v)
+		if err != nil {
+			return Variant{}, err
+		}
+		return NewVariantArray(VT_UI1, saPtr), nil
+	case *IUnknown, *Object, *Type, *Assembly, *AppDomain:
+		// all of these are COM interface pointers to managed objects
+		ptr := reflect.ValueOf(v).Pointer()
+		if ptr == 0 {
+			return Variant{VT: VT_EMPTY}, nil
+		}
+		NewIUnknownFromPtr(ptr).AddRef()
+		return Variant{VT: VT_UNKNOWN, Val: ptr}, nil
+	default:
+		if kind := reflect.ValueOf(value).Kind(); kind == reflect.Slice || kind == reflect.Array {
+			array, err := SafeArrayFrom(value)
+			if err != nil {
+				return Variant{}, err
+			}
+			vt, err := SafeArrayGetVartype(array)
+			if err != nil {
+				SafeArrayDestroy(array)
+				return Variant{}, err
+			}
+			return NewVariantArray(vt, array), nil
+		}
+		return Variant{}, fmt.Errorf("can't convert %T to a Variant", value)
+	}
+}
+
+// ToGo converts the contents of the Variant into the matching Go value:
+//
+//	VT_EMPTY, VT_NULL          nil
+//	VT_I1 ... VT_UI8, VT_INT   int8 ... uint64, VT_INT and VT_UINT become int32 and uint32
+//	VT_R4, VT_R8               float32, float64
+//	VT_BOOL                    bool
+//	VT_DATE                    time.Time, see DateToTime
+//	VT_CY, VT_DECIMAL          Currency, Decimal
+//	VT_ERROR, VT_HRESULT       uint32 holding the SCODE
+//	VT_BSTR                    string
+//	VT_UNKNOWN, VT_DISPATCH    *Object, or nil for a null reference
+//	VT_ARRAY | VT_UI1          []byte
+//	VT_ARRAY | VT_BSTR         []string
+//	VT_ARRAY | anything else   []interface{} with each element converted with ToGo
+//	VT_BYREF | anything        the value pointed to, converted with ToGo
+//
+// The Variant keeps ownership of its memory, the returned value is a copy and a returned *Object holds its own
+// reference
+func (v *Variant) ToGo() (interface{}, error) {
+	if value, ok := v.scalar(); ok {
+		return value, nil
+	}
+	switch {
+	case v.VT == VT_EMPTY, v.VT == VT_NULL:
+		return nil, nil
+	case v.VT == VT_BSTR:
+		if v.Val == 0 {
+			return "", nil
+		}
+		return readUnicodeStr(unsafe.Pointer(v.Val)), nil
+	case v.VT == VT_UNKNOWN, v.VT == VT_DISPATCH:
+		if v.Val == 0 {
+			return nil, nil
+		}
+		return NewObjectFromVariant(v)
+	case v.VT&VT_BYREF != 0:
+		return v.byRefToGo()
+	case v.VT&VT_ARRAY != 0:
+		return arrayToGo(unsafe.Pointer(v.Val), v.VT&^VT_ARRAY)
+	default:
+		return nil, fmt.Errorf("can't convert a Variant of type 0x%04x to a Go value", v.VT)
+	}
+}
+
+// byRefToGo converts the value a VT_BYREF Variant points to
+func (v *Variant) byRefToGo() (interface{}, error) {
+	vt := v.VT &^ VT_BYREF
+	if v.Val == 0 {
+		return nil, nil
+	}
+	if vt == VT_VARIANT {
+		return NewVariantFromPtr(v.Val).ToGo()
+	}
+	size := variantSize(vt)
+	if size == 0 {
+		return nil, fmt.Errorf("can't convert a Variant of type 0x%04x to a Go value", v.VT)
+	}
+	// a shallow copy is enough, ToGo never takes ownership of what the Variant holds
+	var value Variant
+	value.load(vt, (*[16]byte)(unsafe.Pointer(v.Val))[:size])
+	return value.ToGo()
+}
+
+// arrayToGo converts the elements of a one dimensional safe array of type vt into a Go slice
+func arrayToGo(array unsafe.Pointer, vt uint16) (interface{}, error) {
+	if array == nil {
+		return nil, nil
+	}
+	switch vt {
+	case VT_UI1:
+		return SafeArrayToBytes(array)
+	case VT_BSTR:
+		return SafeArrayToStrings(array)
+	case VT_VARIANT:
+		variants, err := SafeArrayToVariants(array)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for i := range variants {
+				VariantClear(&variants[i])
+			}
+		}()
+		out := make([]interface{}, len(variants))
+		for i := range variants {
+			if out[i], err = variants[i].ToGo(); err != nil {
+				releaseValues(out[:i])
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	size := uintptr(SafeArrayGetElemsize(array))
+	if size == 0 || size > variantSize(vt) {
+		return nil, fmt.Errorf("can't convert a safe array of type 0x%04x to a Go value", vt)
+	}
+	data, count, err := safeArrayAccessVector(array, vt)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return []interface{}{}, nil
+	}
+	defer SafeArrayUnaccessData(array)
+	out := make([]interface{}, count)
+	for i := range out {
+		// a shallow copy is enough, ToGo never takes ownership of what the Variant holds
+		var value Variant
+		value.load(vt, unsafe.Slice((*byte)(unsafe.Add(data, uintptr(i)*size)), size))
+		if out[i], err = value.ToGo(); err != nil {
+			releaseValues(out[:i])
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// releaseValues releases the managed objects among values returned by ToGo, including those nested in slices
+func releaseValues(values []interface{}) {
+	for _, value := range values {
+		switch v := value.(type) {
+		case *Object:
+			if v != nil {
+				v.Release()
+			}
+		case []interface{}:
+			releaseValues(v)
+		}
+	}
+}
+
+// VariantClear is a wrapper for the OleAut32.dll VariantClear syscall. It frees whatever the Variant holds (BSTRs,
+// safe arrays, interface references) and sets it to VT_EMPTY
+func VariantClear(v *Variant) error {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	procVariantClear := modOleAuto.MustFindProc("VariantClear")
+	hr, _, _ := procVariantClear.Call(uintptr(unsafe.Pointer(v)))
+	return checkOK(hr, "VariantClear")
+}
+
+// Clear frees whatever the Variant holds with VariantClear. It can be deferred right after the Variant is created:
+//
+//	v, err := NewVariant(value)
+//	if err != nil {
+//		return err
+//	}
+//	defer v.Clear()
+func (v *Variant) Clear() error {
+	return VariantClear(v)
+}
+
+// VariantCopy is a wrapper for the OleAut32.dll VariantCopy syscall. It clears dest and fills it with a deep copy of
+// src, so BSTRs and safe arrays are duplicated and interfaces are AddRef'd
+func VariantCopy(dest, src *Variant) error {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	procVariantCopy := modOleAuto.MustFindProc("VariantCopy")
+	hr, _, _ := procVariantCopy.Call(
+		uintptr(unsafe.Pointer(dest)),
+		uintptr(unsafe.Pointer(src)))
+	return checkOK(hr, "VariantCopy")
+}
+
+// VariantChangeType is a wrapper for the OleAut32.dll VariantChangeType syscall. It converts src to type vt and stores
+// the result in dest, which may be src itself to convert in place. flags are VARIANT_* coercion flags from oleauto.h
+func VariantChangeType(dest, src *Variant, flags uint16, vt uint16) error {
+	modOleAuto := syscall.MustLoadDLL("OleAut32.dll")
+	procVariantChangeType := modOleAuto.MustFindProc("VariantChangeType")
+	hr, _, _ := procVariantChangeType.Call(
+		uintptr(unsafe.Pointer(dest)),
+		uintptr(unsafe.Pointer(src)),
+		uintptr(flags),
+		uintptr(vt))
+	return checkOK(hr, "VariantChangeType")
+}
+
+// ChangeType returns a copy of the Variant converted to type vt, e.g. a VT_BSTR holding "42" to a VT_I4. The result
+// is owned by the caller and should be cleared with VariantClear
+func (v *Variant) ChangeType(vt uint16) (Variant, error) {
+	var dest Variant
+	err := VariantChangeType(&dest, v, 0, vt)
+	return dest, err
 }
@@ -0,0 +1,25 @@
+package clr
+
+import "unsafe"
+
+// VARIANT from oaidl.h, which is 16 bytes on 32-bit Windows
+
+type Variant struct {
+	VT         uint16 // VARTYPE
+	wReserved1 uint16
+	wReserved2 uint16
+	wReserved3 uint16
+	Val        uintptr
+	_          [4]byte
+}
+
+// variantByValue is true because stdcall pushes a VARIANT passed by value onto the stack as four words
+const variantByValue = true
+
+// the layouts have to match oaidl.h exactly, these fail to compile if they don't
+var (
+	_ [unsafe.Sizeof(Variant{}) - 16]byte
+	_ [16 - unsafe.Sizeof(Variant{})]byte
+	_ [unsafe.Sizeof(SafeArray{}) - 24]byte
+	_ [24 - unsafe.Sizeof(SafeArray{})]byte
+)
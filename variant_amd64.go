@@ -0,0 +1,26 @@
+package clr
+
+import "unsafe"
+
+// from https://github.com/go-ole/go-ole/blob/master/variant_amd64.go
+
+type Variant struct {
+	VT         uint16 // VARTYPE
+	wReserved1 uint16
+	wReserved2 uint16
+	wReserved3 uint16
+	Val        uintptr
+	_          [8]byte
+}
+
+// variantByValue is false because the x64 calling convention passes structs larger than 8 bytes, like a VARIANT, as a
+// pointer to a copy made by the caller
+const variantByValue = false
+
+// the layouts have to match oaidl.h exactly, these fail to compile if they don't
+var (
+	_ [unsafe.Sizeof(Variant{}) - 24]byte
+	_ [24 - unsafe.Sizeof(Variant{})]byte
+	_ [unsafe.Sizeof(SafeArray{}) - 32]byte
+	_ [32 - unsafe.Sizeof(SafeArray{})]byte
+)
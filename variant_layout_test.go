@@ -0,0 +1,67 @@
+package clr
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+// the sizes and offsets from oaidl.h for each architecture we build for
+var layouts = map[string]struct {
+	variant, val                 uintptr
+	safeArray, pvData, rgsabound uintptr
+	byValue                      bool
+}{
+	"386":   {variant: 16, val: 8, safeArray: 24, pvData: 12, rgsabound: 16, byValue: true},
+	"amd64": {variant: 24, val: 8, safeArray: 32, pvData: 16, rgsabound: 24},
+	"arm64": {variant: 24, val: 8, safeArray: 32, pvData: 16, rgsabound: 24},
+}
+
+func TestLayout(t *testing.T) {
+	want, ok := layouts[runtime.GOARCH]
+	if !ok {
+		t.Skipf("no layout for %s", runtime.GOARCH)
+	}
+	var v Variant
+	var sa SafeArray
+	got := []struct {
+		name      string
+		got, want uintptr
+	}{
+		{"Sizeof(Variant)", unsafe.Sizeof(v), want.variant},
+		{"Offsetof(Variant.Val)", unsafe.Offsetof(v.Val), want.val},
+		{"Sizeof(SafeArray)", unsafe.Sizeof(sa), want.safeArray},
+		{"Offsetof(SafeArray.pvData)", unsafe.Offsetof(sa.pvData), want.pvData},
+		{"Offsetof(SafeArray.rgsabound)", unsafe.Offsetof(sa.rgsabound), want.rgsabound},
+		{"Sizeof(SafeArrayBound)", unsafe.Sizeof(SafeArrayBound{}), 8},
+	}
+	for _, g := range got {
+		if g.got != g.want {
+			t.Errorf("%s = %d, want %d", g.name, g.got, g.want)
+		}
+	}
+	if variantByValue != want.byValue {
+		t.Errorf("variantByValue = %v on %s", variantByValue, runtime.GOARCH)
+	}
+}
+
+func TestWords(t *testing.T) {
+	v := NewVariantInt64(0x1122334455667788)
+	v.wReserved1 = 0xaaaa
+	v.wReserved2 = 0xbbbb
+	v.wReserved3 = 0xcccc
+	want := [4]uintptr{0xaaaa0014, 0xccccbbbb, 0x55667788, 0x11223344}
+	if got := v.words(); got != want {
+		t.Errorf("words() = %x, want %x", got, want)
+	}
+
+	var sa SafeArray
+	v = NewVariantArray(VT_VARIANT, unsafe.Pointer(&sa))
+	words := v.words()
+	if words[0] != VT_ARRAY|VT_VARIANT {
+		t.Errorf("words()[0] = 0x%x, want 0x%x", words[0], VT_ARRAY|VT_VARIANT)
+	}
+	if runtime.GOARCH == "386" && words[2] != uintptr(unsafe.Pointer(&sa)) {
+		t.Errorf("words()[2] = 0x%x, want the address of the safe array", words[2])
+	}
+}
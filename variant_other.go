@@ -0,0 +1,20 @@
+// +build !386,!amd64,!arm64
+
+package clr
+
+import "unsafe"
+
+// VARIANT from oaidl.h for the architectures Windows doesn't run on, so the platform independent parts of the package
+// still build there. The padding makes it 16 bytes with 32-bit pointers and 24 bytes with 64-bit ones, as on Windows
+
+type Variant struct {
+	VT         uint16 // VARTYPE
+	wReserved1 uint16
+	wReserved2 uint16
+	wReserved3 uint16
+	Val        uintptr
+	_          [unsafe.Sizeof(uintptr(0))]byte
+}
+
+// variantByValue is false because nothing is ever called through a vtable on these architectures
+const variantByValue = false
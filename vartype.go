@@ -0,0 +1,323 @@
+package clr
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+	"unsafe"
+)
+
+// The layout of a VARIANT and a SAFEARRAY, the encoding of the scalar values a VARIANT can hold and the conversions in
+// oledata.go don't depend on Windows, so these files are built on every platform. The Variant struct itself differs
+// between architectures and lives in variant_$GOARCH.go
+
+// from OAld.h
+
+type SafeArray struct {
+	cDims      uint16
+	fFeatures  uint16
+	cbElements uint32
+	cLocks     uint32
+	pvData     uintptr
+	rgsabound  [1]SafeArrayBound
+}
+
+type SafeArrayBound struct {
+	cElements uint32
+	lLbound   int32
+}
+
+// VARENUM values from wtypes.h
+const (
+	VT_EMPTY            = 0x0000
+	VT_NULL             = 0x0001
+	VT_I2               = 0x0002
+	VT_I4               = 0x0003
+	VT_R4               = 0x0004
+	VT_R8               = 0x0005
+	VT_CY               = 0x0006
+	VT_DATE             = 0x0007
+	VT_BSTR             = 0x0008
+	VT_DISPATCH         = 0x0009
+	VT_ERROR            = 0x000A
+	VT_BOOL             = 0x000B
+	VT_VARIANT          = 0x000C
+	VT_UNKNOWN          = 0x000D
+	VT_DECIMAL          = 0x000E
+	VT_I1               = 0x0010
+	VT_UI1              = 0x0011
+	VT_UI2              = 0x0012
+	VT_UI4              = 0x0013
+	VT_I8               = 0x0014
+	VT_UI8              = 0x0015
+	VT_INT              = 0x0016
+	VT_UINT             = 0x0017
+	VT_VOID             = 0x0018
+	VT_HRESULT          = 0x0019
+	VT_PTR              = 0x001A
+	VT_SAFEARRAY        = 0x001B
+	VT_CARRAY           = 0x001C
+	VT_USERDEFINED      = 0x001D
+	VT_LPSTR            = 0x001E
+	VT_LPWSTR           = 0x001F
+	VT_RECORD           = 0x0024
+	VT_INT_PTR          = 0x0025
+	VT_UINT_PTR         = 0x0026
+	VT_FILETIME         = 0x0040
+	VT_BLOB             = 0x0041
+	VT_STREAM           = 0x0042
+	VT_STORAGE          = 0x0043
+	VT_STREAMED_OBJECT  = 0x0044
+	VT_STORED_OBJECT    = 0x0045
+	VT_BLOB_OBJECT      = 0x0046
+	VT_CF               = 0x0047
+	VT_CLSID            = 0x0048
+	VT_VERSIONED_STREAM = 0x0049
+	VT_BSTR_BLOB        = 0x0FFF
+	VT_VECTOR           = 0x1000
+	VT_ARRAY            = 0x2000
+	VT_BYREF            = 0x4000
+	VT_RESERVED         = 0x8000
+	VT_ILLEGAL          = 0xFFFF
+	VT_ILLEGALMASKED    = 0x0FFF
+	VT_TYPEMASK         = 0x0FFF
+)
+
+// VARIANT_BOOL values
+const (
+	VARIANT_TRUE  = -1
+	VARIANT_FALSE = 0
+)
+
+// NewVariantNull returns a VT_NULL Variant, which is how a null reference is passed to managed code
+func NewVariantNull() Variant {
+	return Variant{VT: VT_NULL}
+}
+
+// NewVariantInt8 returns a VT_I1 Variant, a managed sbyte
+func NewVariantInt8(i int8) Variant {
+	v := Variant{VT: VT_I1}
+	*(*int8)(v.payload()) = i
+	return v
+}
+
+// NewVariantInt16 returns a VT_I2 Variant, a managed short
+func NewVariantInt16(i int16) Variant {
+	v := Variant{VT: VT_I2}
+	*(*int16)(v.payload()) = i
+	return v
+}
+
+// NewVariantInt32 returns a VT_I4 Variant, a managed int
+func NewVariantInt32(i int32) Variant {
+	v := Variant{VT: VT_I4}
+	*(*int32)(v.payload()) = i
+	return v
+}
+
+// NewVariantInt64 returns a VT_I8 Variant, a managed long
+func NewVariantInt64(i int64) Variant {
+	v := Variant{VT: VT_I8}
+	*(*int64)(v.payload()) = i
+	return v
+}
+
+// NewVariantUint8 returns a VT_UI1 Variant, a managed byte
+func NewVariantUint8(i uint8) Variant {
+	v := Variant{VT: VT_UI1}
+	*(*uint8)(v.payload()) = i
+	return v
+}
+
+// NewVariantUint16 returns a VT_UI2 Variant, a managed ushort
+func NewVariantUint16(i uint16) Variant {
+	v := Variant{VT: VT_UI2}
+	*(*uint16)(v.payload()) = i
+	return v
+}
+
+// NewVariantUint32 returns a VT_UI4 Variant, a managed uint
+func NewVariantUint32(i uint32) Variant {
+	v := Variant{VT: VT_UI4}
+	*(*uint32)(v.payload()) = i
+	return v
+}
+
+// NewVariantUint64 returns a VT_UI8 Variant, a managed ulong
+func NewVariantUint64(i uint64) Variant {
+	v := Variant{VT: VT_UI8}
+	*(*uint64)(v.payload()) = i
+	return v
+}
+
+// NewVariantFloat32 returns a VT_R4 Variant, a managed float
+func NewVariantFloat32(f float32) Variant {
+	v := Variant{VT: VT_R4}
+	*(*uint32)(v.payload()) = math.Float32bits(f)
+	return v
+}
+
+// NewVariantFloat64 returns a VT_R8 Variant, a managed double
+func NewVariantFloat64(f float64) Variant {
+	v := Variant{VT: VT_R8}
+	*(*uint64)(v.payload()) = math.Float64bits(f)
+	return v
+}
+
+// NewVariantBool returns a VT_BOOL Variant, a managed bool
+func NewVariantBool(b bool) Variant {
+	v := Variant{VT: VT_BOOL}
+	*(*int16)(v.payload()) = VARIANT_FALSE
+	if b {
+		*(*int16)(v.payload()) = VARIANT_TRUE
+	}
+	return v
+}
+
+// NewVariantDate returns a VT_DATE Variant, a managed DateTime, with the wall clock of t. See TimeToDate
+func NewVariantDate(t time.Time) (Variant, error) {
+	date, err := TimeToDate(t)
+	if err != nil {
+		return Variant{}, err
+	}
+	v := Variant{VT: VT_DATE}
+	*(*uint64)(v.payload()) = math.Float64bits(date)
+	return v, nil
+}
+
+// NewVariantCurrency returns a VT_CY Variant, which managed code receives as a decimal
+func NewVariantCurrency(c Currency) Variant {
+	v := Variant{VT: VT_CY}
+	*(*int64)(v.payload()) = int64(c)
+	return v
+}
+
+// NewVariantDecimal returns a VT_DECIMAL Variant, a managed decimal
+func NewVariantDecimal(d Decimal) Variant {
+	var v Variant
+	// a DECIMAL takes up the whole VARIANT, only its first two reserved bytes are shared with the VARTYPE
+	b := (*[16]byte)(unsafe.Pointer(&v))
+	b[2] = d.Scale
+	if d.Negative {
+		b[3] = 0x80 // DECIMAL_NEG
+	}
+	binary.LittleEndian.PutUint32(b[4:], d.Hi32)
+	binary.LittleEndian.PutUint64(b[8:], d.Lo64)
+	v.VT = VT_DECIMAL
+	return v
+}
+
+// NewVariantArray returns a Variant holding the safe array of elements of type vt, e.g. NewVariantArray(VT_BSTR, sa)
+// for a managed string[]. The Variant takes ownership of the safe array
+func NewVariantArray(vt uint16, array unsafe.Pointer) Variant {
+	v := Variant{VT: VT_ARRAY | vt}
+	*(*unsafe.Pointer)(v.payload()) = array
+	return v
+}
+
+// payload returns a pointer to the 8 bytes of the Variant that hold its value
+func (v *Variant) payload() unsafe.Pointer {
+	return unsafe.Pointer(&v.Val)
+}
+
+// load sets the Variant to a value of type vt read from src, which holds the value as it is laid out outside of a
+// Variant, such as an element of a safe array or the target of a VT_BYREF pointer. The Variant doesn't own the value
+func (v *Variant) load(vt uint16, src []byte) {
+	*v = Variant{}
+	if vt == VT_DECIMAL {
+		copy((*[16]byte)(unsafe.Pointer(v))[:], src)
+	} else {
+		copy((*[8]byte)(v.payload())[:], src)
+	}
+	v.VT = vt
+}
+
+// decimal reads the DECIMAL held by a VT_DECIMAL Variant
+func (v *Variant) decimal() Decimal {
+	b := (*[16]byte)(unsafe.Pointer(v))
+	return Decimal{
+		Scale:    b[2],
+		Negative: b[3]&0x80 != 0,
+		Hi32:     binary.LittleEndian.Uint32(b[4:]),
+		Lo64:     binary.LittleEndian.Uint64(b[8:]),
+	}
+}
+
+// words returns the four 32-bit words a 16 byte VARIANT is split into when it is passed by value on 386, where the
+// whole struct is pushed on the stack
+func (v *Variant) words() [4]uintptr {
+	b := (*[16]byte)(unsafe.Pointer(v))
+	var w [4]uintptr
+	for i := range w {
+		w[i] = uintptr(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return w
+}
+
+// scalar returns the value of a Variant holding one of the numeric types, a bool or a date as the matching Go type.
+// The second return value is false for any other type or a date that is out of range
+func (v *Variant) scalar() (interface{}, bool) {
+	p := v.payload()
+	switch v.VT {
+	case VT_I1:
+		return *(*int8)(p), true
+	case VT_I2:
+		return *(*int16)(p), true
+	case VT_I4, VT_INT:
+		return *(*int32)(p), true
+	case VT_I8:
+		return *(*int64)(p), true
+	case VT_UI1:
+		return *(*uint8)(p), true
+	case VT_UI2:
+		return *(*uint16)(p), true
+	case VT_UI4, VT_UINT:
+		return *(*uint32)(p), true
+	case VT_UI8:
+		return *(*uint64)(p), true
+	case VT_R4:
+		return math.Float32frombits(*(*uint32)(p)), true
+	case VT_R8:
+		return math.Float64frombits(*(*uint64)(p)), true
+	case VT_BOOL:
+		return *(*int16)(p) != VARIANT_FALSE, true
+	case VT_CY:
+		return Currency(*(*int64)(p)), true
+	case VT_DATE:
+		t, err := DateToTime(math.Float64frombits(*(*uint64)(p)))
+		return t, err == nil
+	case VT_DECIMAL:
+		return v.decimal(), true
+	case VT_INT_PTR, VT_UINT_PTR:
+		return *(*uintptr)(p), true
+	case VT_ERROR, VT_HRESULT:
+		// an SCODE, e.g. DISP_E_PARAMNOTFOUND for a missing optional parameter
+		return *(*uint32)(p), true
+	default:
+		return nil, false
+	}
+}
+
+// variantSize returns the size of a value of type vt when it is stored outside of a Variant, such as in a safe array
+// or behind a VT_BYREF pointer, or 0 if the type can't be stored in a Variant
+func variantSize(vt uint16) uintptr {
+	if vt&VT_ARRAY != 0 {
+		return unsafe.Sizeof(uintptr(0))
+	}
+	switch vt {
+	case VT_I1, VT_UI1:
+		return 1
+	case VT_I2, VT_UI2, VT_BOOL:
+		return 2
+	case VT_I4, VT_UI4, VT_INT, VT_UINT, VT_R4, VT_ERROR, VT_HRESULT:
+		return 4
+	case VT_I8, VT_UI8, VT_R8, VT_CY, VT_DATE:
+		return 8
+	case VT_DECIMAL:
+		return 16
+	case VT_BSTR, VT_UNKNOWN, VT_DISPATCH, VT_INT_PTR, VT_UINT_PTR:
+		return unsafe.Sizeof(uintptr(0))
+	default:
+		return 0
+	}
+}
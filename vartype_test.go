@@ -0,0 +1,215 @@
+package clr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+// payloadBytes returns the 8 bytes of the Variant that hold its value
+func payloadBytes(v *Variant) []byte {
+	b := *(*[8]byte)(v.payload())
+	return b[:]
+}
+
+func le16(i uint16) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint16(b, i)
+	return b
+}
+
+func le32(i uint32) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint32(b, i)
+	return b
+}
+
+func le64(i uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, i)
+	return b
+}
+
+func TestScalarConstructors(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       Variant
+		vt      uint16
+		payload []byte
+		want    interface{}
+	}{
+		{"int8", NewVariantInt8(-2), VT_I1, []byte{0xfe, 0, 0, 0, 0, 0, 0, 0}, int8(-2)},
+		{"int16", NewVariantInt16(math.MinInt16), VT_I2, le16(0x8000), int16(math.MinInt16)},
+		{"int32", NewVariantInt32(-1), VT_I4, le32(0xffffffff), int32(-1)},
+		{"int64", NewVariantInt64(math.MaxInt64), VT_I8, le64(math.MaxInt64), int64(math.MaxInt64)},
+		{"uint8", NewVariantUint8(0xab), VT_UI1, []byte{0xab, 0, 0, 0, 0, 0, 0, 0}, uint8(0xab)},
+		{"uint16", NewVariantUint16(0xbeef), VT_UI2, le16(0xbeef), uint16(0xbeef)},
+		{"uint32", NewVariantUint32(0xdeadbeef), VT_UI4, le32(0xdeadbeef), uint32(0xdeadbeef)},
+		{"uint64", NewVariantUint64(math.MaxUint64), VT_UI8, le64(math.MaxUint64), uint64(math.MaxUint64)},
+		{"float32", NewVariantFloat32(1.5), VT_R4, le32(math.Float32bits(1.5)), float32(1.5)},
+		{"float64", NewVariantFloat64(-0.25), VT_R8, le64(math.Float64bits(-0.25)), float64(-0.25)},
+		{"true", NewVariantBool(true), VT_BOOL, le16(0xffff), true},
+		{"false", NewVariantBool(false), VT_BOOL, le16(0), false},
+		{"currency", NewVariantCurrency(-12500), VT_CY, le64(uint64(1<<64 - 12500)), Currency(-12500)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.v.VT != test.vt {
+				t.Errorf("VT = 0x%04x, want 0x%04x", test.v.VT, test.vt)
+			}
+			if got := payloadBytes(&test.v); !bytes.Equal(got, test.payload) {
+				t.Errorf("payload = % x, want % x", got, test.payload)
+			}
+			got, ok := test.v.scalar()
+			if !ok {
+				t.Fatalf("scalar() returned false")
+			}
+			if got != test.want {
+				t.Errorf("scalar() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecimalVariant(t *testing.T) {
+	d := Decimal{Scale: 4, Negative: true, Hi32: 0x01020304, Lo64: 0x05060708090a0b0c}
+	v := NewVariantDecimal(d)
+	if v.VT != VT_DECIMAL {
+		t.Errorf("VT = 0x%04x, want 0x%04x", v.VT, VT_DECIMAL)
+	}
+	want := []byte{
+		0x0e, 0x00, 4, 0x80,
+		0x04, 0x03, 0x02, 0x01,
+		0x0c, 0x0b, 0x0a, 0x09, 0x08, 0x07, 0x06, 0x05,
+	}
+	if got := (*[16]byte)(unsafe.Pointer(&v))[:]; !bytes.Equal(got, want) {
+		t.Errorf("DECIMAL = % x, want % x", got, want)
+	}
+	got, ok := v.scalar()
+	if !ok || got != d {
+		t.Errorf("scalar() = %#v, %v, want %#v", got, ok, d)
+	}
+}
+
+func TestDateVariant(t *testing.T) {
+	date, err := DateToTime(1.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVariantDate(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.VT != VT_DATE {
+		t.Errorf("VT = 0x%04x, want 0x%04x", v.VT, VT_DATE)
+	}
+	if got := payloadBytes(&v); !bytes.Equal(got, le64(math.Float64bits(1.5))) {
+		t.Errorf("payload = % x, want the bits of 1.5", got)
+	}
+	got, ok := v.scalar()
+	if !ok || got != date {
+		t.Errorf("scalar() = %v, %v, want %v", got, ok, date)
+	}
+}
+
+func TestNewVariantArray(t *testing.T) {
+	// any pointer will do, NewVariantArray doesn't look at the array
+	array := unsafe.Pointer(new([8]byte))
+	v := NewVariantArray(VT_BSTR, array)
+	if v.VT != VT_ARRAY|VT_BSTR {
+		t.Errorf("VT = 0x%04x, want 0x%04x", v.VT, VT_ARRAY|VT_BSTR)
+	}
+	if v.Val != uintptr(array) {
+		t.Errorf("Val = 0x%x, want the address of the safe array", v.Val)
+	}
+	if _, ok := v.scalar(); ok {
+		t.Errorf("scalar() returned true for an array")
+	}
+}
+
+func TestNonScalar(t *testing.T) {
+	for _, vt := range []uint16{VT_EMPTY, VT_NULL, VT_BSTR, VT_UNKNOWN, VT_DISPATCH, VT_VARIANT, VT_BYREF | VT_I4} {
+		v := Variant{VT: vt}
+		if got, ok := v.scalar(); ok {
+			t.Errorf("scalar() of 0x%04x = %#v, want false", vt, got)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	// the target of a VT_BYREF | VT_I2 pointer, followed by bytes that don't belong to it
+	var v Variant
+	v.VT = VT_BYREF | VT_I2
+	v.Val = 0xffffffff
+	v.load(VT_I2, []byte{0x34, 0x12})
+	if v.VT != VT_I2 {
+		t.Errorf("VT = 0x%04x, want 0x%04x", v.VT, VT_I2)
+	}
+	if got, _ := v.scalar(); got != int16(0x1234) {
+		t.Errorf("scalar() = %#v, want 0x1234", got)
+	}
+	if got := payloadBytes(&v); !bytes.Equal(got, le16(0x1234)) {
+		t.Errorf("payload = % x, the previous value wasn't cleared", got)
+	}
+
+	// an element of a SAFEARRAY(SAFEARRAY(BSTR)) is the pointer to the inner array
+	ptr := make([]byte, unsafe.Sizeof(uintptr(0)))
+	for i := range ptr {
+		ptr[i] = byte(i + 1)
+	}
+	v.load(VT_ARRAY|VT_BSTR, ptr)
+	if v.VT != VT_ARRAY|VT_BSTR {
+		t.Errorf("VT = 0x%04x, want 0x%04x", v.VT, VT_ARRAY|VT_BSTR)
+	}
+	if got := payloadBytes(&v)[:len(ptr)]; !bytes.Equal(got, ptr) {
+		t.Errorf("payload = % x, want % x", got, ptr)
+	}
+
+	d := Decimal{Scale: 28, Hi32: math.MaxUint32, Lo64: math.MaxUint64}
+	src := NewVariantDecimal(d)
+	raw := (*[16]byte)(unsafe.Pointer(&src))[:]
+	// a DECIMAL stored in a safe array has no VARTYPE
+	raw[0], raw[1] = 0, 0
+	v.load(VT_DECIMAL, raw)
+	if got, ok := v.scalar(); !ok || got != d {
+		t.Errorf("scalar() = %#v, %v, want %#v", got, ok, d)
+	}
+}
+
+func TestVariantSize(t *testing.T) {
+	ptrSize := uintptr(8)
+	if runtime.GOARCH == "386" {
+		ptrSize = 4
+	}
+	tests := []struct {
+		vt   uint16
+		want uintptr
+	}{
+		{VT_I1, 1},
+		{VT_UI1, 1},
+		{VT_I2, 2},
+		{VT_BOOL, 2},
+		{VT_I4, 4},
+		{VT_R4, 4},
+		{VT_ERROR, 4},
+		{VT_I8, 8},
+		{VT_R8, 8},
+		{VT_CY, 8},
+		{VT_DATE, 8},
+		{VT_DECIMAL, 16},
+		{VT_BSTR, ptrSize},
+		{VT_UNKNOWN, ptrSize},
+		{VT_INT_PTR, ptrSize},
+		{VT_ARRAY | VT_I4, ptrSize},
+		{VT_VARIANT, 0},
+		{VT_RECORD, 0},
+	}
+	for _, test := range tests {
+		if got := variantSize(test.vt); got != test.want {
+			t.Errorf("variantSize(0x%04x) = %d, want %d", test.vt, got, test.want)
+		}
+	}
+}